@@ -1,8 +1,14 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/speedcrash100/go-yadro-testtask/pkg"
 )
@@ -15,21 +21,128 @@ const (
 	CLIENT_LEFT      = 4
 )
 
+// Output formats
+const (
+	FORMAT_TEXT = "text"
+	FORMAT_JSON = "json"
+)
+
+// Settings or input file cannot be read
+const EXIT_FAILURE = 1
+
+var (
+	ErrFollowFormat = errors.New("-follow works only with text format")
+)
+
+// Commands
+const (
+	COMMAND_SERVE    = "serve"
+	COMMAND_REPL     = "repl"
+	COMMAND_VALIDATE = "validate"
+)
+
 func main() {
-	args := os.Args
-	if len(args) != 2 {
-		fmt.Println("Usage: program <file>")
+	args := os.Args[1:]
+
+	if len(args) > 0 && args[0] == COMMAND_SERVE {
+		runServe(args[1:])
+		return
+	}
+
+	if len(args) > 0 && args[0] == COMMAND_REPL {
+		runRepl(args[1:])
+		return
+	}
+
+	if len(args) > 0 && args[0] == COMMAND_VALIDATE {
+		runValidate(args[1:])
 		return
 	}
 
-	file_path := args[1]
+	runProcess(args)
+}
+
+// Processes input file and prints the result
+func runProcess(args []string) {
+	flags := flag.NewFlagSet("program", flag.ExitOnError)
+	format := flags.String("format", FORMAT_TEXT, "output format: text or json")
+	lenient := flags.Bool("lenient", false, "skip invalid lines and report all of them at the end")
+	clients := flags.Bool("clients", false, "add bills of the clients to the output")
+	stats := flags.Bool("stats", false, "add statistics of the tables to the output")
+	queue_stats := flags.Bool("queue-stats", false, "add statistics of the waiting queue to the output")
+	timeline := flags.Bool("timeline", false, "add chart of the tables occupation and hourly load to the output")
+	apply_simulation := addSimulationFlags(flags)
+	snapshot_path := flags.String("snapshot", "", "write snapshot to the file at the end of input instead of closing the club")
+	restore_path := flags.String("restore", "", "continue from snapshot, the file contains only events after it")
+	stream := flags.Bool("stream", false, "write events as soon as they are processed, text format only")
+	follow := flags.Bool("follow", false, "wait for lines appended to the file, implies -stream, text format only. The day is closed at closing time by the clock, on event after close or on signal; timed events are written only when the next line is read or the day is closed")
+	interval := flags.Duration("interval", time.Second, "how often the file is checked for new lines in -follow mode")
+	flags.Usage = func() {
+		fmt.Println("Usage: program [-format text|json] [-lenient] [-clients] [-stats] [-queue-stats] [-timeline] " + SIMULATION_USAGE + " [-snapshot <file>] [-restore <file>] [-stream] [-follow] [-interval <duration>] <file>")
+		fmt.Println("       program serve [-addr <address>] [-pricing <config>] <file>")
+		fmt.Println("       program validate <file>")
+		fmt.Println("       program repl " + SIMULATION_USAGE)
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(EXIT_FAILURE)
+	}
+
+	if *format != FORMAT_TEXT && *format != FORMAT_JSON {
+		exitWithError(fmt.Errorf("invalid output format: %s", *format))
+	}
+
+	// JSON is written only when the day is closed, so nothing is shown live
+	if *follow && *format == FORMAT_JSON {
+		exitWithError(ErrFollowFormat)
+	}
+
+	file_path := flags.Arg(0)
 
 	o, err := os.Open(file_path)
 	if err != nil {
-		fmt.Println(err)
+		exitWithError(err)
+	}
+	defer o.Close()
+
+	var input io.Reader = o
+	var app pkg.App
+	if *follow {
+		input = followFile(o, *interval, &app)
 	}
 
-	app := pkg.NewApp(o, os.Stdout)
+	app = pkg.NewApp(input, os.Stdout)
+	app.SetLenient(*lenient)
+	app.SetStreaming(*stream || *follow)
+
+	if err := apply_simulation(&app); err != nil {
+		exitWithError(err)
+	}
+
+	// Snapshot keeps settings, so it is restored after them
+	if len(*restore_path) != 0 {
+		if err := restoreSnapshot(&app, *restore_path); err != nil {
+			exitWithError(err)
+		}
+	}
+
+	if len(*snapshot_path) != 0 {
+		snapshot, err := os.Create(*snapshot_path)
+		if err != nil {
+			exitWithError(err)
+		}
+		defer snapshot.Close()
+		app.SetCheckpoint(snapshot)
+	}
+
+	switch *format {
+	case FORMAT_TEXT:
+		app.SetRenderer(pkg.TextRenderer{Clients: *clients, Stats: *stats, Queue: *queue_stats, Timeline: *timeline})
+	case FORMAT_JSON:
+		app.SetRenderer(pkg.JSONRenderer{Pretty: true, Clients: *clients, Stats: *stats, Queue: *queue_stats, Timeline: *timeline})
+	}
 
 	if err := app.Process(); err != nil {
 		if len(os.Getenv("DEBUG")) > 0 {
@@ -39,3 +152,48 @@ func main() {
 	}
 
 }
+
+// Writes error of the settings or the input file and stops the program
+func exitWithError(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(EXIT_FAILURE)
+}
+
+// Reads the file until closing time of the club by the clock or until the
+// process is interrupted
+func followFile(f *os.File, interval time.Duration, app *pkg.App) io.Reader {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	stop := make(chan struct{})
+	go func() {
+		<-signals
+		close(stop)
+	}()
+
+	closing_reached := func() bool {
+		return app.ClosingReached(pkg.TimeFromClock(time.Now()))
+	}
+
+	return pkg.NewFollowReader(f, interval, stop, closing_reached)
+}
+
+func loadPricing(path string) (pkg.PricingConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return pkg.PricingConfig{}, err
+	}
+	defer f.Close()
+
+	return pkg.LoadPricingConfig(f)
+}
+
+func restoreSnapshot(app *pkg.App, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return app.Restore(f)
+}
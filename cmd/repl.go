@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/speedcrash100/go-yadro-testtask/pkg"
+)
+
+// Reads header of the club and then events from standard input one by one
+func runRepl(args []string) {
+	flags := flag.NewFlagSet(COMMAND_REPL, flag.ExitOnError)
+	apply_simulation := addSimulationFlags(flags)
+	flags.Usage = func() {
+		fmt.Println("Usage: program repl " + SIMULATION_USAGE)
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return
+	}
+
+	app := pkg.NewApp(os.Stdin, os.Stdout)
+
+	if err := apply_simulation(&app); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println("Enter number of tables, opening hours and price, then events. Type help for commands")
+
+	repl := pkg.NewRepl(app)
+	repl.Prompt = "> "
+	if err := repl.Run(); err != nil {
+		fmt.Println(err)
+	}
+}
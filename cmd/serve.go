@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/speedcrash100/go-yadro-testtask/pkg"
+)
+
+// Runs HTTP API for the club described by the header of the file
+func runServe(args []string) {
+	flags := flag.NewFlagSet(COMMAND_SERVE, flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "address to listen")
+	pricing_path := flags.String("pricing", "", "pricing config file in json")
+	flags.Usage = func() {
+		fmt.Println("Usage: program serve [-addr <address>] [-pricing <config>] <file>")
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return
+	}
+
+	o, err := os.Open(flags.Arg(0))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer o.Close()
+
+	pricing := pkg.NewHourlyPricing
+	if len(*pricing_path) != 0 {
+		config, err := loadPricing(*pricing_path)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		pricing = config.Policy
+	}
+
+	srv, err := pkg.NewServer(o, pricing)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Printf("Listening on %s\n", *addr)
+	if err := http.ListenAndServe(*addr, srv); err != nil {
+		fmt.Println(err)
+	}
+}
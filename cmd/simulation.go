@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/speedcrash100/go-yadro-testtask/pkg"
+)
+
+// Waiting queue settings
+const (
+	QUEUE_TABLES    = "tables"
+	QUEUE_UNLIMITED = "unlimited"
+
+	OVERFLOW_REJECT = "reject"
+	OVERFLOW_EVICT  = "evict"
+	OVERFLOW_ACCEPT = "accept"
+)
+
+var overflow_policies = map[string]pkg.OverflowPolicy{
+	OVERFLOW_REJECT: pkg.OVERFLOW_REJECT,
+	OVERFLOW_EVICT:  pkg.OVERFLOW_EVICT_OLDEST,
+	OVERFLOW_ACCEPT: pkg.OVERFLOW_ACCEPT,
+}
+
+var (
+	ErrInvalidQueueConfig   = errors.New("invalid queue settings")
+	ErrInvalidSessionLimits = errors.New("invalid session limits")
+)
+
+// Usage of the flags added by addSimulationFlags
+const SIMULATION_USAGE = "[-pricing <config>] [-queue <capacity>] [-overflow reject|evict|accept] [-vip <roster>] [-grace <minutes>] [-max-wait <minutes>] [-max-session <minutes>] [-max-session-tables <limits>]"
+
+// Adds flags which change how the club works. Returned function applies
+// them to the app after the flags are parsed
+func addSimulationFlags(flags *flag.FlagSet) func(*pkg.App) error {
+	pricing_path := flags.String("pricing", "", "pricing config file in json")
+	queue := flags.String("queue", QUEUE_TABLES, "capacity of the waiting queue: number, tables or unlimited")
+	overflow := flags.String("overflow", OVERFLOW_REJECT, "when queue is full: reject newcomer, evict the longest waiting or accept over capacity")
+	vip_path := flags.String("vip", "", "file with VIP clients, one per line")
+	grace := flags.Uint("grace", pkg.RESERVATION_GRACE_DEFAULT, "minutes reserved table waits for the client")
+	max_wait := flags.Uint("max-wait", pkg.WAIT_UNLIMITED, "minutes client waits in the queue before leaving, 0 is until close")
+	max_session := flags.Uint("max-session", pkg.SESSION_UNLIMITED, "minutes client may sit at the table, 0 is not limited")
+	table_sessions := flags.String("max-session-tables", "", "session limits of separate tables: <table>=<minutes>,...")
+
+	return func(app *pkg.App) error {
+		queue_config, err := parseQueueConfig(*queue, *overflow)
+		if err != nil {
+			return err
+		}
+		app.SetQueueConfig(queue_config)
+		app.SetReservationGrace(int(*grace))
+		app.SetWaitLimit(int(*max_wait))
+
+		session_limits, err := parseSessionLimits(*max_session, *table_sessions)
+		if err != nil {
+			return err
+		}
+		app.SetSessionLimits(session_limits)
+
+		if len(*vip_path) != 0 {
+			vip, err := loadRoster(*vip_path)
+			if err != nil {
+				return err
+			}
+			app.SetVIP(vip)
+		}
+
+		if len(*pricing_path) != 0 {
+			config, err := loadPricing(*pricing_path)
+			if err != nil {
+				return err
+			}
+			app.SetPricing(config.Policy)
+		}
+
+		return nil
+	}
+}
+
+func parseQueueConfig(capacity_str, overflow_str string) (pkg.QueueConfig, error) {
+	var config pkg.QueueConfig
+
+	switch capacity_str {
+	case QUEUE_TABLES:
+		config.Capacity = pkg.QUEUE_CAPACITY_TABLES
+	case QUEUE_UNLIMITED:
+		config.Capacity = pkg.QUEUE_UNLIMITED
+	default:
+		capacity, err := strconv.ParseUint(capacity_str, 10, 31)
+		if err != nil {
+			return config, ErrInvalidQueueConfig
+		}
+		config.Capacity = int(capacity)
+	}
+
+	policy, ok := overflow_policies[overflow_str]
+	if !ok {
+		return config, ErrInvalidQueueConfig
+	}
+	config.Overflow = policy
+
+	return config, nil
+}
+
+// Parses limits of separate tables in "<table>=<minutes>,..." format
+func parseSessionLimits(default_limit uint, tables_str string) (pkg.SessionLimits, error) {
+	limits := pkg.SessionLimits{Default: int(default_limit), Tables: make(map[uint]int)}
+	if len(tables_str) == 0 {
+		return limits, nil
+	}
+
+	for _, item := range strings.Split(tables_str, ",") {
+		table_str, minutes_str, ok := strings.Cut(item, "=")
+		if !ok {
+			return limits, ErrInvalidSessionLimits
+		}
+
+		table, err := strconv.ParseUint(table_str, 10, 31)
+		if err != nil || table == 0 {
+			return limits, ErrInvalidSessionLimits
+		}
+
+		minutes, err := strconv.ParseUint(minutes_str, 10, 31)
+		if err != nil {
+			return limits, ErrInvalidSessionLimits
+		}
+
+		limits.Tables[uint(table)] = int(minutes)
+	}
+
+	return limits, nil
+}
+
+func loadRoster(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return pkg.LoadRoster(f)
+}
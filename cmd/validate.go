@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/speedcrash100/go-yadro-testtask/pkg"
+)
+
+// Exit codes of validate command besides EXIT_FAILURE
+const (
+	EXIT_VALID    = 0
+	EXIT_INVALID  = 2 // File has errors
+	EXIT_WARNINGS = 3 // File can be processed but has warnings
+)
+
+// Checks input file and prints found problems in JSON
+func runValidate(args []string) {
+	flags := flag.NewFlagSet(COMMAND_VALIDATE, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Println("Usage: program validate <file>")
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(EXIT_FAILURE)
+	}
+
+	o, err := os.Open(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(EXIT_FAILURE)
+	}
+	defer o.Close()
+
+	app := pkg.NewApp(o, os.Stdout)
+	validation, err := app.Validate()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(EXIT_FAILURE)
+	}
+
+	if err := validation.WriteJSON(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(EXIT_FAILURE)
+	}
+
+	switch {
+	case !validation.Valid():
+		os.Exit(EXIT_INVALID)
+	case len(validation.Warnings) != 0:
+		os.Exit(EXIT_WARNINGS)
+	}
+}
@@ -2,8 +2,8 @@ package pkg
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
-	"fmt"
 	"io"
 	"strconv"
 	"strings"
@@ -17,141 +17,436 @@ var (
 type App struct {
 	state State
 
-	input  *bufio.Scanner
-	output io.Writer
+	input    *bufio.Scanner
+	output   io.Writer
+	renderer Renderer
+
+	line        int // Number of the last read line
+	lenient     bool
+	diagnostics ParseErrors
+	pricing     PricingFactory
+	registry    *EventRegistry
+
+	totals  Totals       // Sum over all days of multi-day log
+	results bytes.Buffer // Results of closed days, written when the whole input is processed
+
+	checkpoint io.Writer // Snapshot is written here at the end of input
+	header     bool      // Header is read from input or taken from snapshot
+
+	streaming   bool
+	day_written bool // Beginning of the current day is already written in streaming mode
+}
+
+// Revenue of the tables over several days
+type Totals struct {
+	Profit []uint
+	Usage  []Duration
+}
+
+// Adds results of the working day
+func (t *Totals) Add(s *State) {
+	if t.Profit == nil {
+		t.Profit = make([]uint, s.table_count)
+		t.Usage = make([]Duration, s.table_count)
+	}
+
+	for i := uint(0); i < s.table_count; i++ {
+		t.Profit[i] += s.tables_profit[i]
+		t.Usage[i] = t.Usage[i].Add(s.tables_usage[i])
+	}
 }
 
 func NewApp(input io.Reader, output io.Writer) App {
 	scanner := bufio.NewScanner(input)
 	scanner.Split(bufio.ScanLines)
-	return App{MakeState(), scanner, output}
+	return App{
+		state:    MakeState(),
+		input:    scanner,
+		output:   output,
+		renderer: TextRenderer{},
+		pricing:  NewHourlyPricing,
+		registry: default_registry,
+	}
 }
 
-func (app *App) Process() error {
-	if err := app.readClubInfo(); err != nil {
+// Change format of the output. Text is used by default
+func (app *App) SetRenderer(renderer Renderer) {
+	app.renderer = renderer
+}
+
+// Change set of known input events
+func (app *App) SetEventRegistry(registry *EventRegistry) {
+	app.registry = registry
+}
+
+// Change how clients are billed. By default every started hour is paid
+// by the price from the input file
+func (app *App) SetPricing(pricing PricingFactory) {
+	app.pricing = pricing
+}
+
+// Change capacity of the waiting queue and what happens when it is full.
+// By default capacity equals to the number of tables and newcomers leave
+func (app *App) SetQueueConfig(config QueueConfig) {
+	app.state.queue_config = config
+}
+
+// Change how many minutes reserved table waits for the client
+func (app *App) SetReservationGrace(minutes int) {
+	app.state.reservation_grace = minutes
+}
+
+// Change how many minutes clients wait in the queue before leaving.
+// By default they wait until the club closes
+func (app *App) SetWaitLimit(minutes int) {
+	app.state.wait_limit = minutes
+}
+
+// Change how many minutes clients may sit at the tables. By default
+// sessions are not limited
+func (app *App) SetSessionLimits(limits SessionLimits) {
+	app.state.session_limits = limits
+}
+
+// Mark clients which are seated before others
+func (app *App) SetVIP(clients []string) {
+	for _, client := range clients {
+		app.state.vip[client] = struct{}{}
+	}
+}
+
+// Reads list of clients, one per line. Empty lines are skipped
+func LoadRoster(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	clients := make([]string, 0)
+
+	for line := 1; scanner.Scan(); line++ {
+		client := strings.TrimSpace(scanner.Text())
+		if len(client) == 0 {
+			continue
+		}
+
+		if !ValidClientName(client) {
+			return nil, &ParseError{Line: line, Column: 1, Field: FIELD_CLIENT, Text: client, Err: ErrInvalidEventFormat}
+		}
+
+		clients = append(clients, client)
+	}
+
+	return clients, scanner.Err()
+}
+
+// In lenient mode invalid lines are skipped and reported at the end instead
+// of stopping processing on the first one
+func (app *App) SetLenient(lenient bool) {
+	app.lenient = lenient
+}
+
+// In streaming mode events are written as soon as they are processed instead
+// of the end of working day. Output is the same unless processing is stopped
+// by invalid line: events before it are already written.
+// Works only with renderers which implement StreamRenderer
+func (app *App) SetStreaming(streaming bool) {
+	app.streaming = streaming
+}
+
+// Errors collected in lenient mode
+func (app *App) Diagnostics() ParseErrors {
+	return app.diagnostics
+}
+
+// Reads next line of input
+func (app *App) scan() bool {
+	if !app.input.Scan() {
+		return false
+	}
+
+	app.line++
+	return true
+}
+
+// Reads next line which must exist
+func (app *App) nextLine() (string, error) {
+	if !app.scan() {
+		if app.input.Err() == nil {
+			return "", ErrEOF
+		}
+		return "", app.input.Err()
+	}
+
+	return app.input.Text(), nil
+}
+
+// Handles error in the current line.
+// In strict mode writes the line to output and returns error which must stop processing.
+// In lenient mode remembers error and returns nil
+func (app *App) report(line string, err error) error {
+	var parse_err *ParseError
+	if !errors.As(err, &parse_err) {
+		parse_err = &ParseError{Column: 1, Err: err}
+	}
+	parse_err.Line = app.line
+	parse_err.Text = line
+
+	if app.lenient {
+		app.diagnostics = append(app.diagnostics, parse_err)
+		return nil
+	}
+
+	app.renderer.RenderError(app.output, line, parse_err)
+	return parse_err
+}
+
+// Writes collected diagnostics and returns them as error if any
+func (app *App) finishLenient() error {
+	if len(app.diagnostics) == 0 {
+		return nil
+	}
+
+	if err := app.renderer.RenderDiagnostics(app.output, app.diagnostics); err != nil {
 		return err
 	}
 
-	var prev_time Time
+	return app.diagnostics
+}
+
+func (app *App) Process() error {
+	if !app.header {
+		if err := app.readClubInfo(); err != nil {
+			return err
+		}
 
-	for app.input.Scan() {
+		// Events cannot be checked without valid header
+		if len(app.diagnostics) != 0 {
+			return app.finishLenient()
+		}
+	}
+
+	for app.scan() {
 		str := app.input.Text()
 		if len(str) == 0 {
 			continue
 		}
 
-		event, err := NewInputEvent(str, app.state)
+		// Single word line begins the next day of multi-day log
+		if !strings.Contains(str, " ") {
+			if err := app.startDay(str); err != nil {
+				return err
+			}
+			continue
+		}
+
+		event, err := app.registry.Parse(str, app.state)
 		if err != nil {
-			fmt.Fprintln(app.output, str)
-			return err
+			if err := app.report(str, err); err != nil {
+				return err
+			}
+			continue
 		}
 
-		// If event AFTER close then we need to generate client left event now
-		if app.state.time_end.Less(event.Time()) {
-			app.state.OnClubClose()
+		if _, err := app.state.Apply(event); err != nil {
+			if err := app.report(event.String(), &ParseError{Column: 1, Field: FIELD_TIME, Err: err}); err != nil {
+				return err
+			}
+			continue
 		}
 
-		// Invalid order of events
-		if !prev_time.LessOrEquals(event.Time()) {
-			fmt.Fprintln(app.output, event)
-			return ErrInvalidOrderOfEvent
+		if err := app.flush(); err != nil {
+			return err
 		}
+	}
 
-		prev_time = event.Time()
-		app.state.current_time = event.Time()
-		app.state.events = append(app.state.events, event)
+	if err := app.input.Err(); err != nil {
+		return err
+	}
 
-		event.Translate(&app.state)
+	// Working day is not over yet
+	if app.checkpoint != nil {
+		if err := app.writeResults(); err != nil {
+			return err
+		}
+		return app.WriteSnapshot(app.checkpoint)
+	}
 
+	if err := app.closeDay(); err != nil {
+		return err
 	}
 
-	// Means it wasn't run in loop because there were not event after close
-	if app.state.current_time.LessOrEquals(app.state.time_end) {
-		app.state.OnClubClose()
+	if err := app.writeResults(); err != nil {
+		return err
 	}
 
-	fmt.Fprintln(app.output, app.state.time_start)
+	if len(app.state.date) != 0 {
+		if err := app.renderer.RenderTotal(app.output, app.totals); err != nil {
+			return err
+		}
+	}
 
-	for _, e := range app.state.events {
-		fmt.Fprintln(app.output, e)
+	return app.finishLenient()
+}
+
+// Is the closing time of the club reached at the time of the day or by
+// processed events. False until the header is read
+func (app *App) ClosingReached(now Time) bool {
+	if !app.header {
+		return false
 	}
 
-	fmt.Fprintln(app.output, app.state.time_end)
+	day := app.state.WorkDay()
+	return day.LessOrEquals(day.End, now) || day.AfterClose(app.state.last_time)
+}
 
-	for i := uint(0); i < app.state.table_count; i++ {
-		fmt.Fprintf(app.output, "%d %d %v\n", i+1, app.state.tables_profit[i], app.state.tables_usage[i])
+// Closes current working day and begins the day from date line
+func (app *App) startDay(line string) error {
+	date, err := MakeDate(line)
+	if err != nil {
+		return app.report(line, newFieldError(FIELD_DATE, []string{line}, 0, err))
 	}
 
+	if date <= app.state.date {
+		return app.report(line, newFieldError(FIELD_DATE, []string{line}, 0, ErrInvalidOrderOfEvent))
+	}
+
+	// Events before the first date are treated as separate day
+	if len(app.state.date) != 0 || len(app.state.events) != 0 || app.day_written {
+		if err := app.closeDay(); err != nil {
+			return err
+		}
+	}
+
+	app.state.NextDay(date)
 	return nil
 }
 
-func (app *App) readClubInfo() error {
-	// First line - Get tables count
-	if !app.input.Scan() {
-		if app.input.Err() == nil {
-			return ErrEOF
+// Closes the club and writes results of the working day. Without streaming
+// results are kept until writeResults, so invalid line of the later day is
+// the only output
+func (app *App) closeDay() error {
+	app.state.OnClubClose()
+
+	if stream, ok := app.streamRenderer(); ok {
+		if err := app.flush(); err != nil {
+			return err
+		}
+		if err := stream.RenderDayEnd(app.output, &app.state); err != nil {
+			return err
 		}
-		return app.input.Err()
+		app.day_written = false
+	} else if err := app.renderer.RenderResult(&app.results, &app.state); err != nil {
+		return err
 	}
 
-	tables_str := app.input.Text()
-	tables_count, err := strconv.ParseUint(tables_str, 10, 0)
-	if err != nil {
-		fmt.Fprintln(app.output, tables_str)
-		return err
+	app.totals.Add(&app.state)
+	return nil
+}
+
+// Writes results of closed days
+func (app *App) writeResults() error {
+	_, err := app.results.WriteTo(app.output)
+	return err
+}
+
+// Renderer used for streaming if it is enabled and supported
+func (app *App) streamRenderer() (StreamRenderer, bool) {
+	if !app.streaming {
+		return nil, false
 	}
 
-	app.state.InitTables(uint(tables_count))
+	stream, ok := app.renderer.(StreamRenderer)
+	return stream, ok
+}
 
-	// Second line - Get open times
-	if !app.input.Scan() {
-		if app.input.Err() == nil {
-			return ErrEOF
+// Writes processed events in streaming mode and forgets them
+func (app *App) flush() error {
+	stream, ok := app.streamRenderer()
+	if !ok {
+		return nil
+	}
+
+	if !app.day_written {
+		if err := stream.RenderDayStart(app.output, &app.state); err != nil {
+			return err
 		}
-		return app.input.Err()
+		app.day_written = true
 	}
 
-	times_str := app.input.Text()
-	times_strs := strings.Split(times_str, " ")
-	if len(times_strs) != 2 {
-		fmt.Fprintln(app.output, times_str)
-		return ErrInvalidTimeFormat
+	if err := stream.RenderEvents(app.output, app.state.events); err != nil {
+		return err
 	}
 
-	start_time, err := MakeTime(times_strs[0])
+	app.state.events = nil
+	return nil
+}
+
+func (app *App) readClubInfo() error {
+	// First line - Get tables count
+	tables_str, err := app.nextLine()
 	if err != nil {
-		fmt.Fprintln(app.output, times_str)
 		return err
 	}
-	end_time, err := MakeTime(times_strs[1])
+
+	tables_count, err := strconv.ParseUint(tables_str, 10, 0)
 	if err != nil {
-		fmt.Fprintln(app.output, times_str)
-		return err
+		if err := app.report(tables_str, newFieldError(FIELD_TABLES, []string{tables_str}, 0, err)); err != nil {
+			return err
+		}
+	} else {
+		app.state.InitTables(uint(tables_count))
 	}
 
-	app.state.time_start = start_time
-	app.state.time_end = end_time
-	if !(start_time.Less(end_time)) {
-		fmt.Fprintln(app.output, times_str)
-		return ErrInvalidTimeFormat
+	// Second line - Get open times
+	times_str, err := app.nextLine()
+	if err != nil {
+		return err
 	}
 
-	// Third line - Get tables count
-	if !app.input.Scan() {
-		if app.input.Err() == nil {
-			return ErrEOF
+	if err := app.readOpenTimes(times_str); err != nil {
+		if err := app.report(times_str, err); err != nil {
+			return err
 		}
-		return app.input.Err()
 	}
 
-	price_str := app.input.Text()
-	price, err := strconv.ParseUint(price_str, 10, 0)
+	// Third line - Get price
+	price_str, err := app.nextLine()
 	if err != nil {
-		fmt.Fprintln(app.output, price_str)
 		return err
 	}
+
+	price, err := strconv.ParseUint(price_str, 10, 0)
+	if err != nil {
+		if err := app.report(price_str, newFieldError(FIELD_PRICE, []string{price_str}, 0, err)); err != nil {
+			return err
+		}
+	}
 	app.state.price = uint(price)
+	app.state.pricing = app.pricing(app.state.price)
+	app.header = true
 
 	return nil
 
 }
+
+// Parses "start end" line
+func (app *App) readOpenTimes(times_str string) error {
+	times_strs := strings.Split(times_str, " ")
+	if len(times_strs) != 2 {
+		return newFieldError(FIELD_ARGUMENTS, times_strs, 0, ErrInvalidTimeFormat)
+	}
+
+	start_time, err := MakeTime(times_strs[0])
+	if err != nil {
+		return newFieldError(FIELD_OPEN, times_strs, 0, err)
+	}
+	end_time, err := MakeTime(times_strs[1])
+	if err != nil {
+		return newFieldError(FIELD_CLOSE, times_strs, 1, err)
+	}
+
+	app.state.SetWorkDay(start_time, end_time)
+	// Club may be open through midnight, but not for zero time
+	if start_time == end_time {
+		return newFieldError(FIELD_CLOSE, times_strs, 1, ErrInvalidTimeFormat)
+	}
+
+	return nil
+}
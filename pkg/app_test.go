@@ -3,6 +3,7 @@ package pkg
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -11,9 +12,26 @@ import (
 )
 
 type testCase struct {
-	name string
-	in   *os.File
-	out  *os.File
+	name     string
+	in       *os.File
+	out      *os.File
+	renderer Renderer // Text renderer without reports if nil
+	validate bool     // Output is the result of validation instead of processing
+}
+
+// Fixtures processed with other renderers or validated. Output file is
+// compared the same way as output of the input file with the same name
+var fixture_variants = []struct {
+	input    string
+	output   string
+	renderer Renderer
+	validate bool
+}{
+	{"stock.txt", "stock_json.txt", JSONRenderer{Pretty: true}, false},
+	{"stock.txt", "stock_clients.txt", TextRenderer{Clients: true}, false},
+	{"stock.txt", "stock_stats.txt", TextRenderer{Stats: true}, false},
+	{"stock.txt", "stock_timeline.txt", TextRenderer{Timeline: true}, false},
+	{"stock.txt", "stock_validate.txt", nil, true},
 }
 
 // Compares 2 stream line by line
@@ -82,7 +100,20 @@ func TestApp(t *testing.T) {
 			continue
 		}
 
-		test_cases = append(test_cases, testCase{file.Name(), in, out})
+		test_cases = append(test_cases, testCase{name: file.Name(), in: in, out: out})
+	}
+
+	for _, v := range fixture_variants {
+		in, err := os.Open(input_files_dir + "/" + v.input)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		out, err := os.Open(output_files_dir + "/" + v.output)
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+
+		test_cases = append(test_cases, testCase{v.output, in, out, v.renderer, v.validate})
 	}
 
 	if len(test_cases) == 0 {
@@ -93,9 +124,22 @@ func TestApp(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			real_output := bytes.NewBufferString("")
 			app := NewApp(tc.in, real_output)
+			if tc.renderer != nil {
+				app.SetRenderer(tc.renderer)
+			}
 
-			err := app.Process() // Ignore errors here
-			t.Logf("app process error: %v", err)
+			if tc.validate {
+				validation, err := app.Validate()
+				if err != nil {
+					t.Fatalf("validate error: %v", err)
+				}
+				if err := validation.WriteJSON(real_output); err != nil {
+					t.Fatalf("write error: %v", err)
+				}
+			} else {
+				err := app.Process() // Ignore errors here
+				t.Logf("app process error: %v", err)
+			}
 
 			output := real_output.String()
 			output_stream := strings.NewReader(output)
@@ -108,3 +152,554 @@ func TestApp(t *testing.T) {
 	}
 
 }
+
+func TestAppLenient(t *testing.T) {
+	input := strings.Join([]string{
+		"2",
+		"09:00 19:00",
+		"10",
+		"09:10 1 a",
+		"09:15 1 B",
+		"9:20 1 c",
+		"",
+		"09:30 2 a 3",
+		"09:40 2 a 1",
+		"09:35 1 d",
+		"09:50 7 e",
+	}, "\n")
+
+	real_output := bytes.NewBufferString("")
+	app := NewApp(strings.NewReader(input), real_output)
+	app.SetLenient(true)
+
+	err := app.Process()
+	if err == nil {
+		t.Fatalf("Expected errors")
+	}
+
+	expected := []struct {
+		line   int
+		column int
+		field  string
+		err    error
+	}{
+		{5, 9, FIELD_CLIENT, ErrInvalidEventFormat},
+		{6, 1, FIELD_TIME, ErrInvalidTimeFormat},
+		{8, 11, FIELD_TABLE, ErrInvalidEventFormat},
+		{10, 1, FIELD_TIME, ErrInvalidOrderOfEvent},
+		{11, 7, FIELD_ID, ErrUnknownEventType},
+	}
+
+	diagnostics := app.Diagnostics()
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(diagnostics), diagnostics)
+	}
+
+	for i, e := range expected {
+		d := diagnostics[i]
+		if d.Line != e.line || d.Column != e.column || d.Field != e.field {
+			t.Errorf("Invalid position of error %d: %v", i, d)
+		}
+
+		if !errors.Is(d, e.err) {
+			t.Errorf("Error %d does not wrap '%v': %v", i, e.err, d)
+		}
+
+		if !errors.Is(err, e.err) {
+			t.Errorf("Returned error does not wrap '%v'", e.err)
+		}
+	}
+
+	// Valid events are still processed
+	if !strings.Contains(real_output.String(), "09:40 2 a 1\n") {
+		t.Errorf("Valid events missing in output:\n%s", real_output.String())
+	}
+}
+
+func TestStateTableStatsOvernight(t *testing.T) {
+	input := strings.Join([]string{
+		"2",
+		"22:00 02:00",
+		"10",
+		"22:30 1 a",
+		"22:30 2 a 1",
+		"01:15 4 a",
+	}, "\n")
+
+	app := NewApp(strings.NewReader(input), io.Discard)
+	if err := app.Process(); err != nil {
+		t.Fatalf("app process error: %v", err)
+	}
+
+	stats := app.state.TableStats()
+	if stats[0].Sessions != 1 || stats[0].Usage != MakeDuration(2, 45) || stats[0].Idle != MakeDuration(1, 15) || stats[0].Utilization != 68 {
+		t.Errorf("Invalid stats of the first table: %+v", stats[0])
+	}
+	if stats[0].BusiestHour == nil || *stats[0].BusiestHour != (Time{23, 0}) {
+		t.Errorf("Busiest hour must be 23:00, got %v", stats[0].BusiestHour)
+	}
+
+	if stats[1].Sessions != 0 || stats[1].Idle != MakeDuration(4, 0) || stats[1].Utilization != 0 || stats[1].BusiestHour != nil {
+		t.Errorf("Invalid stats of the free table: %+v", stats[1])
+	}
+}
+
+func TestAppQueueStats(t *testing.T) {
+	input := strings.Join([]string{
+		"1",
+		"09:00 19:00",
+		"10",
+		"09:10 1 a",
+		"09:10 2 a 1",
+		"09:20 1 b",
+		"09:20 3 b",
+		"09:30 1 c",
+		"09:30 3 c",
+		"10:00 4 a",
+		"10:30 1 d",
+		"10:30 3 d",
+	}, "\n")
+
+	real_output := bytes.NewBufferString("")
+	app := NewApp(strings.NewReader(input), real_output)
+	app.SetRenderer(TextRenderer{Queue: true})
+
+	if err := app.Process(); err != nil {
+		t.Fatalf("app process error: %v", err)
+	}
+
+	// b waited 00:40, d waited until close, c walked away
+	expected := QUEUE_HEADER + "\n2 04:35 08:30 1 1 1\n"
+	if !strings.HasSuffix(real_output.String(), expected) {
+		t.Errorf("Invalid queue report:\n%s", real_output.String())
+	}
+}
+
+func TestAppTimelineLegend(t *testing.T) {
+	input := strings.Join([]string{
+		"1",
+		"09:00 11:00",
+		"10",
+		"09:00 1 a",
+		"09:00 2 a 1",
+		"09:10 1 b",
+		"09:20 4 b",
+		"09:30 1 c",
+		"09:30 3 c",
+		"09:40 4 c",
+		"10:00 4 a",
+	}, "\n")
+
+	real_output := bytes.NewBufferString("")
+	app := NewApp(strings.NewReader(input), real_output)
+	app.SetRenderer(TextRenderer{Timeline: true})
+
+	if err := app.Process(); err != nil {
+		t.Fatalf("app process error: %v", err)
+	}
+
+	// Clients who have not taken a table are not in the legend
+	expected := strings.Join([]string{
+		"  |09  10  |",
+		"1 |aaaa....|",
+		"a a",
+		HOURLY_HEADER,
+	}, "\n")
+	if !strings.Contains(real_output.String(), expected) {
+		t.Errorf("Expected timeline:\n%s\nReal output:\n%s", expected, real_output.String())
+	}
+}
+
+func TestStateHourlyLoadClosedHalfHour(t *testing.T) {
+	input := strings.Join([]string{
+		"1",
+		"09:30 11:30",
+		"10",
+		"09:40 1 a",
+		"09:40 2 a 1",
+		"10:10 1 b",
+		"10:10 3 b",
+		"10:20 1 c",
+		"10:20 3 c",
+		"10:50 4 a",
+	}, "\n")
+
+	app := NewApp(strings.NewReader(input), io.Discard)
+	app.SetQueueConfig(QueueConfig{QUEUE_UNLIMITED, OVERFLOW_REJECT})
+	if err := app.Process(); err != nil {
+		t.Fatalf("app process error: %v", err)
+	}
+
+	expected := []HourlyLoad{{Time{9, 0}, 1, 0}, {Time{10, 0}, 1, 2}, {Time{11, 0}, 1, 1}}
+	hourly := app.state.HourlyLoad()
+	if fmt.Sprint(hourly) != fmt.Sprint(expected) {
+		t.Errorf("Expected hourly load %v, got %v", expected, hourly)
+	}
+
+	// The club is closed in the first and the last half of hour
+	rows := app.state.timelineRows()
+	if len(rows) != 1 || rows[0] != "  aaaaabbb  " {
+		t.Errorf("Invalid timeline rows: %q", rows)
+	}
+}
+
+func TestAppTimelineWholeDay(t *testing.T) {
+	cases := []struct {
+		name  string
+		end   string
+		hours int
+	}{
+		{"close at the opening hour", "09:00", 24},
+		{"close after the opening hour", "09:15", 25},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			input := strings.Join([]string{"1", "09:30 " + c.end, "10", "09:40 1 a", "09:40 2 a 1"}, "\n")
+
+			for _, renderer := range []Renderer{TextRenderer{Timeline: true}, JSONRenderer{Timeline: true}} {
+				app := NewApp(strings.NewReader(input), io.Discard)
+				app.SetRenderer(renderer)
+				if err := app.Process(); err != nil {
+					t.Fatalf("app process error: %v", err)
+				}
+
+				if hours := len(app.state.HourlyLoad()); hours != c.hours {
+					t.Errorf("Expected %d hours, got %d", c.hours, hours)
+				}
+			}
+		})
+	}
+}
+
+func TestAppQueueOverflow(t *testing.T) {
+	input := strings.Join([]string{
+		"1",
+		"09:00 19:00",
+		"10",
+		"09:10 1 a",
+		"09:10 2 a 1",
+		"09:20 1 b",
+		"09:20 3 b",
+		"09:30 1 c",
+		"09:30 3 c",
+		"10:00 4 a",
+	}, "\n")
+
+	test_cases := []struct {
+		name      string
+		config    QueueConfig
+		vip       []string
+		events    []string // Events after c started waiting
+		walkaways uint
+	}{
+		{
+			"Reject",
+			QueueConfig{QUEUE_CAPACITY_TABLES, OVERFLOW_REJECT},
+			nil,
+			[]string{"09:30 11 c", "10:00 4 a", "10:00 12 b 1", "19:00 11 b"},
+			1,
+		},
+		{
+			"Evict oldest",
+			QueueConfig{1, OVERFLOW_EVICT_OLDEST},
+			nil,
+			[]string{"09:30 11 b", "10:00 4 a", "10:00 12 c 1", "19:00 11 c"},
+			1,
+		},
+		{
+			"Accept",
+			QueueConfig{1, OVERFLOW_ACCEPT},
+			nil,
+			[]string{"09:30 13 QueueOverCapacity", "10:00 4 a", "10:00 12 b 1", "19:00 11 b", "19:00 11 c"},
+			0,
+		},
+		{
+			"Unlimited",
+			QueueConfig{QUEUE_UNLIMITED, OVERFLOW_REJECT},
+			nil,
+			[]string{"10:00 4 a", "10:00 12 b 1", "19:00 11 b", "19:00 11 c"},
+			0,
+		},
+		{
+			"Zero capacity evict",
+			QueueConfig{0, OVERFLOW_EVICT_OLDEST},
+			nil,
+			[]string{"09:30 11 c", "10:00 4 a", "19:00"},
+			2,
+		},
+		{
+			"Evict keeps waiting VIP",
+			QueueConfig{1, OVERFLOW_EVICT_OLDEST},
+			[]string{"b"},
+			[]string{"09:30 11 c", "10:00 4 a", "10:00 12 b 1", "19:00 11 b"},
+			1,
+		},
+		{
+			"VIP evicts regular",
+			QueueConfig{1, OVERFLOW_EVICT_OLDEST},
+			[]string{"c"},
+			[]string{"09:30 11 b", "10:00 4 a", "10:00 12 c 1", "19:00 11 c"},
+			1,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			real_output := bytes.NewBufferString("")
+			app := NewApp(strings.NewReader(input), real_output)
+			app.SetQueueConfig(tc.config)
+			app.SetVIP(tc.vip)
+
+			if err := app.Process(); err != nil {
+				t.Fatalf("app process error: %v", err)
+			}
+
+			expected := "09:30 3 c\n" + strings.Join(tc.events, "\n") + "\n"
+			if !strings.Contains(real_output.String(), expected) {
+				t.Errorf("Expected events:\n%s\nReal output:\n%s", expected, real_output.String())
+			}
+
+			if walkaways := app.state.QueueStats().Walkaways; walkaways != tc.walkaways {
+				t.Errorf("Expected %d walkaways, got %d", tc.walkaways, walkaways)
+			}
+		})
+	}
+}
+
+func TestAppWaitLimit(t *testing.T) {
+	test_cases := []struct {
+		name   string
+		input  []string
+		events []string
+	}{
+		{
+			"Timeout between events",
+			[]string{"1", "09:00 19:00", "10", "09:10 1 a", "09:10 2 a 1", "09:20 1 b", "09:20 3 b", "09:30 1 c", "09:30 3 c", "10:00 4 a"},
+			[]string{"09:30 3 c", "09:50 11 b", "10:00 4 a", "10:00 12 c 1", "19:00 11 c"},
+		},
+		{
+			"Timeout before close",
+			[]string{"1", "09:00 19:00", "10", "09:10 1 a", "09:10 2 a 1", "18:20 1 b", "18:20 3 b", "18:40 1 c", "18:40 3 c"},
+			[]string{"18:40 3 c", "18:50 11 b", "19:00 11 a", "19:00 11 c"},
+		},
+		{
+			"Timeout before reservation release",
+			[]string{"2", "09:00 19:00", "10", "09:00 5 r 2 09:00 12:00", "09:10 1 a", "09:10 2 a 1", "09:20 1 b", "09:20 3 b", "10:30 1 c"},
+			[]string{"09:20 3 b", "09:50 11 b", "10:00 15 r 2", "10:30 1 c"},
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			real_output := bytes.NewBufferString("")
+			app := NewApp(strings.NewReader(strings.Join(tc.input, "\n")), real_output)
+			app.SetQueueConfig(QueueConfig{QUEUE_UNLIMITED, OVERFLOW_REJECT})
+			app.SetReservationGrace(60)
+			app.SetWaitLimit(30)
+
+			if err := app.Process(); err != nil {
+				t.Fatalf("app process error: %v", err)
+			}
+
+			expected := strings.Join(tc.events, "\n") + "\n"
+			if !strings.Contains(real_output.String(), expected) {
+				t.Errorf("Expected events:\n%s\nReal output:\n%s", expected, real_output.String())
+			}
+		})
+	}
+}
+
+func TestAppSessionLimits(t *testing.T) {
+	test_cases := []struct {
+		name       string
+		input      []string
+		limits     SessionLimits
+		wait_limit int
+		events     []string
+	}{
+		{
+			"Waiting client takes the table",
+			[]string{"1", "09:00 19:00", "10", "09:10 1 a", "09:10 2 a 1", "09:20 1 b", "09:20 3 b", "10:30 1 c"},
+			SessionLimits{Default: 60},
+			WAIT_UNLIMITED,
+			[]string{"09:20 3 b", "10:10 16 a 1", "10:10 12 b 1", "10:30 1 c", "11:10 16 b 1", "19:00 11 a", "19:00 11 b", "19:00 11 c", "19:00", "1 20 02:00"},
+		},
+		{
+			"Limit of separate table",
+			[]string{"2", "09:00 19:00", "10", "09:10 1 a", "09:10 2 a 1", "09:10 1 b", "09:10 2 b 2", "10:00 1 c"},
+			SessionLimits{Tables: map[uint]int{2: 30}},
+			WAIT_UNLIMITED,
+			[]string{"09:10 2 b 2", "09:40 16 b 2", "10:00 1 c"},
+		},
+		{
+			"Client gave up before the table is freed",
+			[]string{"1", "09:00 19:00", "10", "09:10 1 a", "09:10 2 a 1", "09:20 1 b", "09:20 3 b", "09:45 1 c", "09:45 3 c", "11:00 1 d"},
+			SessionLimits{Default: 60},
+			30,
+			[]string{"09:45 3 c", "09:50 11 b", "10:10 16 a 1", "10:10 12 c 1", "11:00 1 d"},
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			real_output := bytes.NewBufferString("")
+			app := NewApp(strings.NewReader(strings.Join(tc.input, "\n")), real_output)
+			app.SetQueueConfig(QueueConfig{QUEUE_UNLIMITED, OVERFLOW_REJECT})
+			app.SetSessionLimits(tc.limits)
+			app.SetWaitLimit(tc.wait_limit)
+
+			if err := app.Process(); err != nil {
+				t.Fatalf("app process error: %v", err)
+			}
+
+			expected := strings.Join(tc.events, "\n") + "\n"
+			if !strings.Contains(real_output.String(), expected) {
+				t.Errorf("Expected events:\n%s\nReal output:\n%s", expected, real_output.String())
+			}
+		})
+	}
+}
+
+func TestAppVIP(t *testing.T) {
+	input := strings.Join([]string{
+		"1",
+		"09:00 19:00",
+		"10",
+		"09:10 1 a",
+		"09:10 2 a 1",
+		"09:20 1 b",
+		"09:20 3 b",
+		"09:30 1 vip",
+		"09:30 3 vip",
+		"10:00 4 a",
+	}, "\n")
+
+	vip, err := LoadRoster(strings.NewReader("vip\n\nother\n"))
+	if err != nil {
+		t.Fatalf("Failed to load roster: %v", err)
+	}
+
+	real_output := bytes.NewBufferString("")
+	app := NewApp(strings.NewReader(input), real_output)
+	app.SetQueueConfig(QueueConfig{QUEUE_UNLIMITED, OVERFLOW_REJECT})
+	app.SetVIP(vip)
+
+	if err := app.Process(); err != nil {
+		t.Fatalf("app process error: %v", err)
+	}
+
+	expected := strings.Join([]string{
+		"09:30 3 vip",
+		"09:30 14 vip 1",
+		"10:00 4 a",
+		"10:00 12 vip 1",
+		"19:00 11 b",
+		"19:00 11 vip",
+	}, "\n") + "\n"
+
+	if !strings.Contains(real_output.String(), expected) {
+		t.Errorf("Expected events:\n%s\nReal output:\n%s", expected, real_output.String())
+	}
+
+	if _, err := LoadRoster(strings.NewReader("vip\nVIP\n")); err == nil {
+		t.Errorf("Expected error for invalid client name")
+	}
+}
+
+// Gives input line by line and calls on_read before every read
+type lineReader struct {
+	lines   []string
+	on_read func()
+}
+
+func (r *lineReader) Read(p []byte) (int, error) {
+	r.on_read()
+
+	if len(r.lines) == 0 {
+		return 0, io.EOF
+	}
+
+	n := copy(p, r.lines[0]+"\n")
+	r.lines = r.lines[1:]
+	return n, nil
+}
+
+func TestAppStreaming(t *testing.T) {
+	input_files_dir := "../test_cases/input"
+
+	input_files, err := os.ReadDir(input_files_dir)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	for _, file := range input_files {
+		data, err := os.ReadFile(input_files_dir + "/" + file.Name())
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+
+		for _, lenient := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s lenient %v", file.Name(), lenient), func(t *testing.T) {
+				batch_output := bytes.NewBufferString("")
+				batch := NewApp(bytes.NewReader(data), batch_output)
+				batch.SetLenient(lenient)
+				batch_err := batch.Process()
+
+				real_output := bytes.NewBufferString("")
+				app := NewApp(bytes.NewReader(data), real_output)
+				app.SetLenient(lenient)
+				app.SetStreaming(true)
+				err := app.Process()
+
+				if fmt.Sprint(err) != fmt.Sprint(batch_err) {
+					t.Fatalf("Expected error: %v, got: %v", batch_err, err)
+				}
+
+				// Events before invalid line are already written
+				var parse_err *ParseError
+				if !lenient && errors.As(err, &parse_err) {
+					if !strings.HasSuffix(real_output.String(), batch_output.String()) {
+						t.Errorf("Expected output ending with:\n%s\nReal output:\n%s", batch_output.String(), real_output.String())
+					}
+					return
+				}
+
+				if real_output.String() != batch_output.String() {
+					t.Errorf("Expected:\n%s\nReal output:\n%s", batch_output.String(), real_output.String())
+				}
+			})
+		}
+	}
+}
+
+func TestAppStreamingLive(t *testing.T) {
+	real_output := bytes.NewBufferString("")
+	written := make([]string, 0) // Output before every read
+
+	input := &lineReader{
+		lines: []string{
+			"1",
+			"09:00 19:00",
+			"10",
+			"09:10 1 a",
+			"09:10 2 a 1",
+			"09:20 1 b",
+			"09:20 3 b",
+			"10:00 4 a",
+		},
+		on_read: func() { written = append(written, real_output.String()) },
+	}
+
+	app := NewApp(input, real_output)
+	app.SetStreaming(true)
+	if err := app.Process(); err != nil {
+		t.Fatalf("app process error: %v", err)
+	}
+
+	// The last read finds end of input
+	expected := "09:00\n09:10 1 a\n09:10 2 a 1\n09:20 1 b\n09:20 3 b\n10:00 4 a\n10:00 12 b 1\n"
+	if written[len(written)-1] != expected {
+		t.Errorf("Expected written before end of input:\n%s\nReal output:\n%s", expected, written[len(written)-1])
+	}
+}
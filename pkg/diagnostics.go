@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Names of the fields reported in ParseError
+const (
+	FIELD_TABLES    = "tables"
+	FIELD_OPEN      = "open"
+	FIELD_CLOSE     = "close"
+	FIELD_PRICE     = "price"
+	FIELD_DATE      = "date"
+	FIELD_TIME      = "time"
+	FIELD_ID        = "id"
+	FIELD_CLIENT    = "client"
+	FIELD_TABLE     = "table"
+	FIELD_START     = "start"
+	FIELD_END       = "end"
+	FIELD_ARGUMENTS = "arguments"
+)
+
+// Error in the input with position of the problem.
+// Wraps one of sentinel errors, so errors.Is can be used
+type ParseError struct {
+	Line   int    // Line number starting from 1. 0 if unknown
+	Column int    // Column of failed field starting from 1
+	Field  string // Name of failed field
+	Text   string // Whole line
+	Err    error  // Reason
+}
+
+// Creates error for the field located in pieces[index] of the line split by spaces.
+// If index is out of range, column points to the end of the line
+func newFieldError(field string, pieces []string, index int, err error) *ParseError {
+	column := 1
+	for i := 0; i < index && i < len(pieces); i++ {
+		column += len(pieces[i]) + 1
+	}
+
+	return &ParseError{
+		Column: column,
+		Field:  field,
+		Text:   strings.Join(pieces, " "),
+		Err:    err,
+	}
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s: %v", e.Line, e.Column, e.Field, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// All errors found in lenient mode
+type ParseErrors []*ParseError
+
+func (errs ParseErrors) Error() string {
+	if len(errs) == 1 {
+		return errs[0].Error()
+	}
+
+	return fmt.Sprintf("%d errors in input, first: %v", len(errs), errs[0])
+}
+
+func (errs ParseErrors) Unwrap() []error {
+	out := make([]error, 0, len(errs))
+	for _, e := range errs {
+		out = append(out, e)
+	}
+	return out
+}
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
-	"strings"
 	"unicode"
 )
 
@@ -16,10 +15,14 @@ const (
 	EVENT_ID_IN_CLIENT_TAKE_A_SEAT    = 2
 	EVENT_ID_IN_CLIENT_CLIENT_WAITING = 3
 	EVENT_ID_IN_CLIENT_LEFT           = 4
+	EVENT_ID_IN_TABLE_RESERVED        = 5
 
 	EVENT_ID_OUT_CLIENT_LEFT        = 11
 	EVENT_ID_OUT_CLIENT_TAKE_A_SEAT = 12
 	EVENT_ID_OUT_ERROR              = 13
+	EVENT_ID_OUT_VIP_SKIPPED_QUEUE  = 14
+	EVENT_ID_OUT_RESERVATION_FREED  = 15
+	EVENT_ID_OUT_SESSION_EXPIRED    = 16
 )
 
 // Error messages
@@ -29,6 +32,11 @@ const (
 	MSG_PLACE_IS_BUSY                  = "PlaceIsBusy"
 	MSG_CLIENT_UNKNOWN                 = "ClientUnknown"
 	MSG_WAITING_WHILE_HAVE_FREE_SPACE  = "ICanWaitNoLonger!"
+	MSG_QUEUE_OVER_CAPACITY            = "QueueOverCapacity"
+	MSG_TABLE_RESERVED                 = "TableReserved"
+	MSG_INVALID_RESERVATION            = "InvalidReservation"
+	MSG_RESERVATION_CONFLICT           = "AlreadyReserved"
+	MSG_CLIENT_ALREADY_SEATED          = "AlreadySeated"
 )
 
 var (
@@ -36,56 +44,24 @@ var (
 	ErrUnknownEventType   = errors.New("invalid event type")
 )
 
+// Parses event from the line using default registry. Returns *ParseError on failure
 func NewInputEvent(description string, state State) (InputEvent, error) {
-	pieces := strings.Split(description, " ")
-
-	if len(pieces) < 3 {
-		// 3 pieces minimum: time, id, client
-		return nil, ErrInvalidEventFormat
-	}
-
-	id, err := strconv.Atoi(pieces[1])
-	if err != nil {
-		return nil, err
-	}
+	return default_registry.Parse(description, state)
+}
 
-	time, err := MakeTime(pieces[0])
-	if err != nil {
-		return nil, err
+// Client name may contain only lowercase letters, digits and '_'
+func ValidClientName(client string) bool {
+	if len(client) == 0 {
+		return false
 	}
 
-	client := pieces[2]
-
 	for _, ch := range client {
 		if !((unicode.IsLetter(ch) && unicode.IsLower(ch)) || unicode.IsDigit(ch) || ch == '_') {
-			return nil, ErrInvalidEventFormat
+			return false
 		}
 	}
 
-	remaining_pieces := pieces[3:]
-
-	var event InputEvent
-
-	switch id {
-	case EVENT_ID_IN_CLIENT_ENTERED:
-		event = NewClientEnteredInputEvent(time, client)
-	case EVENT_ID_IN_CLIENT_TAKE_A_SEAT:
-		event, err = NewClientTakeASeatInputEvent(time, client, remaining_pieces, state)
-	case EVENT_ID_IN_CLIENT_CLIENT_WAITING:
-		event = NewClientWaitingInputEvent(time, client)
-	case EVENT_ID_IN_CLIENT_LEFT:
-		event = NewClientLeftInputEvent(time, client)
-	}
-
-	if err != nil {
-		return nil, err
-	}
-
-	if event == nil {
-		return nil, ErrUnknownEventType
-	}
-
-	return event, nil
+	return true
 }
 
 // Base Event interface
@@ -140,6 +116,10 @@ func (e ClientAssociatedEvent) String() string {
 	return e.BaseEvent.String() + " " + e.client
 }
 
+func (e ClientAssociatedEvent) Client() string {
+	return e.client
+}
+
 // Client Entered INPUT event
 type ClientEnteredInputEvent struct {
 	ClientAssociatedEvent
@@ -172,21 +152,8 @@ type ClientTakeASeatInputEvent struct {
 	table_nmb uint
 }
 
-func NewClientTakeASeatInputEvent(time Time, client string, parts []string, state State) (InputEvent, error) {
-	if len(parts) != 1 {
-		return nil, ErrInvalidEventFormat
-	}
-
-	table_nmb, err := strconv.ParseUint(parts[0], 10, 0)
-	if err != nil {
-		return nil, err
-	}
-
-	if table_nmb == 0 || state.table_count < uint(table_nmb) {
-		return nil, ErrInvalidEventFormat
-	}
-
-	return &ClientTakeASeatInputEvent{MakeClientAssociatedEvent(EVENT_ID_IN_CLIENT_TAKE_A_SEAT, time, client), uint(table_nmb)}, nil
+func NewClientTakeASeatInputEvent(time Time, client string, table_nmb uint) InputEvent {
+	return &ClientTakeASeatInputEvent{MakeClientAssociatedEvent(EVENT_ID_IN_CLIENT_TAKE_A_SEAT, time, client), table_nmb}
 }
 
 func (e *ClientTakeASeatInputEvent) Translate(s *State) {
@@ -196,19 +163,39 @@ func (e *ClientTakeASeatInputEvent) Translate(s *State) {
 		return
 	}
 
+	if s.ReservedForOther(e.table_nmb, e.client) {
+		error_event := NewErrorOutputEvent(e, MSG_TABLE_RESERVED)
+		s.events = append(s.events, error_event)
+		return
+	}
+
 	if !s.Known(e.client) {
 		error_event := NewErrorOutputEvent(e, MSG_CLIENT_UNKNOWN)
 		s.events = append(s.events, error_event)
 		return
 	}
 
+	// Client moves from another table, which is billed separately
+	previous_table, moving := s.TableOf(e.client)
+	if moving {
+		s.LeaveTable(e.client)
+	}
+
 	s.OccupyTable(e.table_nmb, e.client)
+
+	if moving {
+		s.SeatFromQueue(previous_table)
+	}
 }
 
 func (e *ClientTakeASeatInputEvent) String() string {
 	return e.ClientAssociatedEvent.String() + " " + fmt.Sprintf("%d", e.table_nmb)
 }
 
+func (e *ClientTakeASeatInputEvent) Table() uint {
+	return e.table_nmb
+}
+
 type ClientWaitingInputEvent struct {
 	ClientAssociatedEvent
 }
@@ -219,20 +206,43 @@ func NewClientWaitingInputEvent(time Time, client string) InputEvent {
 
 func (e *ClientWaitingInputEvent) Translate(s *State) {
 
-	if s.HaveEmptyTable() {
+	// Seated client would take the second table from the queue
+	if _, seated := s.TableOf(e.client); seated {
+		error_event := NewErrorOutputEvent(e, MSG_CLIENT_ALREADY_SEATED)
+		s.events = append(s.events, error_event)
+		return
+	}
+
+	if s.HaveEmptyTableFor(e.client) {
 		error_event := NewErrorOutputEvent(e, MSG_WAITING_WHILE_HAVE_FREE_SPACE)
 		s.events = append(s.events, error_event)
 		return
 	}
 
 	if s.queue.IsFull() {
-		s.ClientLeave(e.client)
-		event := NewClientLeftOutputEvent(s.current_time, e.client)
-		s.events = append(s.events, event)
+		switch s.queue_config.Overflow {
+		case OVERFLOW_EVICT_OLDEST:
+			// Queue of zero capacity has nobody to evict. Newcomer does not
+			// evict clients of higher priority and leaves instead
+			lowest, err := s.queue.LowestPriority()
+			if err == nil && lowest <= s.queuePriority(e.client) {
+				oldest, _ := s.queue.PopLowest()
+				s.walkAway(oldest)
+				s.Enqueue(e.client)
+				return
+			}
+		case OVERFLOW_ACCEPT:
+			s.EnqueueOverCapacity(e.client)
+			error_event := NewErrorOutputEvent(e, MSG_QUEUE_OVER_CAPACITY)
+			s.events = append(s.events, error_event)
+			return
+		}
+
+		s.walkAway(e.client)
 		return
 	}
 
-	s.queue.Push(e.client)
+	s.Enqueue(e.client)
 
 }
 
@@ -256,13 +266,53 @@ func (e *ClientLeftInputEvent) Translate(s *State) {
 		return
 	}
 
-	if !s.queue.IsEmpty() {
-		client_to_place, _ := s.queue.Pop()
-		s.OccupyTable(freed_table, client_to_place)
-		occupy_event := NewClientTakenSeatOutputEvent(s.current_time, client_to_place, freed_table)
-		s.events = append(s.events, occupy_event)
+	s.SeatFromQueue(freed_table)
+
+}
+
+// Client reserves the table for the window of time
+type TableReservedInputEvent struct {
+	ClientAssociatedEvent
+	table_nmb uint
+	start     Time
+	end       Time
+}
+
+func NewTableReservedInputEvent(time Time, client string, table_nmb uint, start, end Time) InputEvent {
+	return &TableReservedInputEvent{MakeClientAssociatedEvent(EVENT_ID_IN_TABLE_RESERVED, time, client), table_nmb, start, end}
+}
+
+func (e *TableReservedInputEvent) Translate(s *State) {
+	day := s.WorkDay()
+
+	reservation := Reservation{Client: e.client, Table: e.table_nmb, Start: e.start, End: e.end}
+
+	// Window must be in opening hours and not in the past. Reservation
+	// which would be released already is not accepted
+	valid := day.Less(e.start, e.end) &&
+		day.LessOrEquals(s.time_start, e.start) &&
+		day.LessOrEquals(e.end, s.time_end) &&
+		day.Less(e.Time(), e.end) &&
+		day.LessOrEquals(e.Time(), s.releaseTime(&reservation))
+	if !valid {
+		error_event := NewErrorOutputEvent(e, MSG_INVALID_RESERVATION)
+		s.events = append(s.events, error_event)
+		return
 	}
 
+	if !s.Reserve(reservation) {
+		error_event := NewErrorOutputEvent(e, MSG_RESERVATION_CONFLICT)
+		s.events = append(s.events, error_event)
+		return
+	}
+}
+
+func (e *TableReservedInputEvent) String() string {
+	return fmt.Sprintf("%s %d %v %v", e.ClientAssociatedEvent.String(), e.table_nmb, e.start, e.end)
+}
+
+func (e *TableReservedInputEvent) Table() uint {
+	return e.table_nmb
 }
 
 type ClientLeftOutputEvent struct {
@@ -286,6 +336,66 @@ func (e *ClientTakenSeatOutputEvent) String() string {
 	return e.ClientAssociatedEvent.String() + " " + fmt.Sprintf("%d", e.table_nmb)
 }
 
+func (e *ClientTakenSeatOutputEvent) Table() uint {
+	return e.table_nmb
+}
+
+// Reserved client has not come in time and the table is free for others
+type ReservationReleasedOutputEvent struct {
+	ClientAssociatedEvent
+	table_nmb uint
+}
+
+func NewReservationReleasedOutputEvent(time Time, client string, table_nmb uint) Event {
+	return &ReservationReleasedOutputEvent{MakeClientAssociatedEvent(EVENT_ID_OUT_RESERVATION_FREED, time, client), table_nmb}
+}
+
+func (e *ReservationReleasedOutputEvent) String() string {
+	return e.ClientAssociatedEvent.String() + " " + fmt.Sprintf("%d", e.table_nmb)
+}
+
+func (e *ReservationReleasedOutputEvent) Table() uint {
+	return e.table_nmb
+}
+
+// Client has reached the session limit and the table is free for others.
+// Client stays in the club
+type SessionExpiredOutputEvent struct {
+	ClientAssociatedEvent
+	table_nmb uint
+}
+
+func NewSessionExpiredOutputEvent(time Time, client string, table_nmb uint) Event {
+	return &SessionExpiredOutputEvent{MakeClientAssociatedEvent(EVENT_ID_OUT_SESSION_EXPIRED, time, client), table_nmb}
+}
+
+func (e *SessionExpiredOutputEvent) String() string {
+	return e.ClientAssociatedEvent.String() + " " + fmt.Sprintf("%d", e.table_nmb)
+}
+
+func (e *SessionExpiredOutputEvent) Table() uint {
+	return e.table_nmb
+}
+
+// VIP client was put in the queue before other clients
+type ClientSkippedQueueOutputEvent struct {
+	ClientAssociatedEvent
+	skipped uint
+}
+
+func NewClientSkippedQueueOutputEvent(time Time, client string, skipped uint) Event {
+	return &ClientSkippedQueueOutputEvent{MakeClientAssociatedEvent(EVENT_ID_OUT_VIP_SKIPPED_QUEUE, time, client), skipped}
+}
+
+func (e *ClientSkippedQueueOutputEvent) String() string {
+	return e.ClientAssociatedEvent.String() + " " + fmt.Sprintf("%d", e.skipped)
+}
+
+// Number of clients who will wait longer because of VIP
+func (e *ClientSkippedQueueOutputEvent) Skipped() uint {
+	return e.skipped
+}
+
 // Error event
 type ErrorOutputEvent struct {
 	BaseEvent
@@ -305,3 +415,7 @@ func NewErrorOutputEvent(parent Event, msg string) Event {
 func (e ErrorOutputEvent) String() string {
 	return e.BaseEvent.String() + " " + e.message
 }
+
+func (e ErrorOutputEvent) Message() string {
+	return e.message
+}
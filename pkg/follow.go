@@ -0,0 +1,41 @@
+package pkg
+
+import (
+	"io"
+	"time"
+)
+
+// Reader of the file which is still written. At the end of the file it waits
+// for appended data instead of returning io.EOF until it is stopped
+type FollowReader struct {
+	r        io.Reader
+	interval time.Duration // How often the file is checked for new data
+	stop     <-chan struct{}
+	done     func() bool // Checked at the end of the file, nil if only stop is used
+}
+
+func NewFollowReader(r io.Reader, interval time.Duration, stop <-chan struct{}, done func() bool) *FollowReader {
+	return &FollowReader{r, interval, stop, done}
+}
+
+func (f *FollowReader) Read(p []byte) (int, error) {
+	for {
+		n, err := f.r.Read(p)
+		if 0 < n {
+			return n, nil
+		}
+		if err != io.EOF {
+			return 0, err
+		}
+
+		if f.done != nil && f.done() {
+			return 0, io.EOF
+		}
+
+		select {
+		case <-f.stop:
+			return 0, io.EOF
+		case <-time.After(f.interval):
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package pkg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFollowReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.txt")
+	if err := os.WriteFile(path, []byte("1\n09:00 19:00\n10\n09:10 1 a\n"), 0644); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	defer f.Close()
+
+	stop := make(chan struct{})
+	real_output := bytes.NewBufferString("")
+	app := NewApp(NewFollowReader(f, time.Millisecond, stop, nil), real_output)
+	app.SetStreaming(true)
+
+	result := make(chan error)
+	go func() { result <- app.Process() }()
+
+	// Line is written in two parts
+	appended, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	defer appended.Close()
+
+	appended.WriteString("09:10 2 ")
+	time.Sleep(10 * time.Millisecond)
+	appended.WriteString("a 1\n10:00 4 a\n")
+	time.Sleep(10 * time.Millisecond)
+	close(stop)
+
+	if err := <-result; err != nil {
+		t.Fatalf("app process error: %v", err)
+	}
+
+	expected := "09:00\n09:10 1 a\n09:10 2 a 1\n10:00 4 a\n19:00\n1 10 00:50\n"
+	if real_output.String() != expected {
+		t.Errorf("Expected:\n%s\nReal output:\n%s", expected, real_output.String())
+	}
+}
+
+func TestFollowReaderStopClosesDay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.txt")
+	if err := os.WriteFile(path, []byte("1\n09:00 19:00\n10\n09:10 1 a\n09:10 2 a 1\n09:20 1 b\n09:20 3 b\n"), 0644); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	defer f.Close()
+
+	stop := make(chan struct{})
+	real_output := bytes.NewBufferString("")
+	app := NewApp(NewFollowReader(f, time.Millisecond, stop, nil), real_output)
+	app.SetStreaming(true)
+	app.SetWaitLimit(30)
+
+	result := make(chan error)
+	go func() { result <- app.Process() }()
+
+	// Input stops in the middle of the day, timers fire when it is stopped
+	time.Sleep(10 * time.Millisecond)
+	close(stop)
+
+	if err := <-result; err != nil {
+		t.Fatalf("app process error: %v", err)
+	}
+
+	expected := "09:00\n09:10 1 a\n09:10 2 a 1\n09:20 1 b\n09:20 3 b\n09:50 11 b\n19:00 11 a\n19:00\n1 100 09:50\n"
+	if real_output.String() != expected {
+		t.Errorf("Expected:\n%s\nReal output:\n%s", expected, real_output.String())
+	}
+}
+
+func TestFollowReaderClosing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.txt")
+	if err := os.WriteFile(path, []byte("1\n09:00 19:00\n10\n09:10 1 a\n"), 0644); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	defer f.Close()
+
+	var app App
+	done := func() bool { return app.ClosingReached(Time{12, 0}) }
+	real_output := bytes.NewBufferString("")
+	app = NewApp(NewFollowReader(f, time.Millisecond, nil, done), real_output)
+
+	// Clock has not reached closing time, so the event after close ends the day
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		appended, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Errorf("Error: %v", err)
+			return
+		}
+		defer appended.Close()
+		appended.WriteString("19:30 1 b\n")
+	}()
+
+	if err := app.Process(); err != nil {
+		t.Fatalf("app process error: %v", err)
+	}
+
+	expected := "09:00\n09:10 1 a\n19:00 11 a\n19:30 1 b\n19:30 13 NotOpenYet\n19:00\n1 0 00:00\n"
+	if real_output.String() != expected {
+		t.Errorf("Expected:\n%s\nReal output:\n%s", expected, real_output.String())
+	}
+}
+
+func TestAppClosingReached(t *testing.T) {
+	app := NewApp(bytes.NewBufferString("1\n20:00 04:00\n10\n"), bytes.NewBufferString(""))
+	if app.ClosingReached(Time{5, 0}) {
+		t.Errorf("Closing reached before header is read")
+	}
+
+	if err := app.Process(); err != nil {
+		t.Fatalf("app process error: %v", err)
+	}
+
+	test_cases := []struct {
+		now      Time
+		expected bool
+	}{
+		{Time{19, 0}, false},
+		{Time{23, 0}, false},
+		{Time{3, 59}, false},
+		{Time{4, 0}, true},
+		{Time{11, 0}, true},
+	}
+
+	for _, tc := range test_cases {
+		if app.ClosingReached(tc.now) != tc.expected {
+			t.Errorf("Closing reached at %v must be %v", tc.now, tc.expected)
+		}
+	}
+}
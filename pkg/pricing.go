@@ -0,0 +1,162 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+var (
+	ErrInvalidPricing = errors.New("invalid pricing config")
+)
+
+// Time spent by the client at the table
+type Session struct {
+	Client string
+	Table  uint
+	Start  Time
+	End    Time
+
+	// Already paid by the client earlier in the working day
+	Paid uint
+}
+
+// Calculates how much the client must pay for the session
+type PricingPolicy interface {
+	Charge(session Session) uint
+}
+
+// Creates pricing policy using price from the input file
+type PricingFactory func(price uint) PricingPolicy
+
+// Every started hour is paid by fixed price. Used by default
+type HourlyPricing struct {
+	Price uint
+}
+
+func NewHourlyPricing(price uint) PricingPolicy {
+	return HourlyPricing{price}
+}
+
+func (p HourlyPricing) Charge(session Session) uint {
+	return session.End.Diff(session.Start).HoursUp() * p.Price
+}
+
+// Rate in the window of time
+type HappyHour struct {
+	Start Time `json:"start"`
+	End   Time `json:"end"`
+	Rate  uint `json:"rate"`
+}
+
+// Configurable pricing. All rates are per hour
+type RatePricing struct {
+	Rate       uint          // Default rate
+	TableRates map[uint]uint // Rates for specific tables
+	PerMinute  bool          // Pay for minutes used instead of every started hour
+	Minimum    uint          // Minimum charge for the session
+	HappyHours []HappyHour   // Windows of time with different rate
+	DailyCap   uint          // Maximum paid by the client in the working day. 0 if there is no cap
+}
+
+// Rate of the table at the time
+func (p RatePricing) rateAt(table uint, t Time) uint {
+	for _, hh := range p.HappyHours {
+		if t.Between(hh.Start, hh.End) {
+			return hh.Rate
+		}
+	}
+
+	if rate, ok := p.TableRates[table]; ok {
+		return rate
+	}
+
+	return p.Rate
+}
+
+func (p RatePricing) Charge(session Session) uint {
+	start := session.Start.InMinutes()
+	duration := session.End.Diff(session.Start).Minutes()
+
+	var charge uint
+	if p.PerMinute {
+		var sum uint
+		for m := 0; m < duration; m++ {
+			sum += p.rateAt(session.Table, TimeFromMinutes(start+m))
+		}
+		// Round up to the whole money
+		charge = (sum + MINUTES_IN_HOUR - 1) / MINUTES_IN_HOUR
+	} else {
+		// Every started hour is paid by the rate at its beginning
+		for m := 0; m < duration; m += MINUTES_IN_HOUR {
+			charge += p.rateAt(session.Table, TimeFromMinutes(start+m))
+		}
+	}
+
+	// Table switch in the same minute is not a session to pay for
+	if 0 < duration && charge < p.Minimum {
+		charge = p.Minimum
+	}
+
+	if p.DailyCap != 0 {
+		if p.DailyCap <= session.Paid {
+			return 0
+		}
+		if remaining := p.DailyCap - session.Paid; remaining < charge {
+			charge = remaining
+		}
+	}
+
+	return charge
+}
+
+// Pricing config file format
+type PricingConfig struct {
+	Rate       uint          `json:"rate"` // If 0 price from input file is used
+	TableRates map[uint]uint `json:"table_rates"`
+	PerMinute  bool          `json:"per_minute"`
+	Minimum    uint          `json:"minimum"`
+	HappyHours []HappyHour   `json:"happy_hours"`
+	DailyCap   uint          `json:"daily_cap"`
+}
+
+func LoadPricingConfig(r io.Reader) (PricingConfig, error) {
+	var config PricingConfig
+
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return config, err
+	}
+
+	for table := range config.TableRates {
+		if table == 0 {
+			return config, ErrInvalidPricing
+		}
+	}
+
+	for _, hh := range config.HappyHours {
+		if hh.Start == hh.End {
+			return config, ErrInvalidPricing
+		}
+	}
+
+	return config, nil
+}
+
+// Creates pricing policy from config. Can be used as PricingFactory
+func (c PricingConfig) Policy(price uint) PricingPolicy {
+	rate := c.Rate
+	if rate == 0 {
+		rate = price
+	}
+
+	return RatePricing{
+		Rate:       rate,
+		TableRates: c.TableRates,
+		PerMinute:  c.PerMinute,
+		Minimum:    c.Minimum,
+		HappyHours: c.HappyHours,
+		DailyCap:   c.DailyCap,
+	}
+}
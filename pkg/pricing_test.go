@@ -0,0 +1,88 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPricingCharge(t *testing.T) {
+	test_cases := []struct {
+		name    string
+		policy  PricingPolicy
+		session Session
+		charge  uint
+	}{
+		{"Hourly", NewHourlyPricing(10), Session{Table: 1, Start: Time{10, 0}, End: Time{12, 1}}, 30},
+		{"Hourly over midnight", NewHourlyPricing(10), Session{Table: 1, Start: Time{23, 30}, End: Time{0, 30}}, 10},
+		{"Rate same as hourly", RatePricing{Rate: 10}, Session{Table: 1, Start: Time{10, 0}, End: Time{12, 1}}, 30},
+		{"Table rate", RatePricing{Rate: 10, TableRates: map[uint]uint{2: 20}}, Session{Table: 2, Start: Time{10, 0}, End: Time{11, 0}}, 20},
+		{"Per minute", RatePricing{Rate: 60, PerMinute: true}, Session{Table: 1, Start: Time{10, 0}, End: Time{10, 25}}, 25},
+		{"Per minute round up", RatePricing{Rate: 10, PerMinute: true}, Session{Table: 1, Start: Time{10, 0}, End: Time{10, 7}}, 2},
+		{"Minimum", RatePricing{Rate: 10, Minimum: 25}, Session{Table: 1, Start: Time{10, 0}, End: Time{10, 30}}, 25},
+		{"Minimum of empty session", RatePricing{Rate: 10, Minimum: 25}, Session{Table: 1, Start: Time{10, 0}, End: Time{10, 0}}, 0},
+		{
+			"Happy hours hourly",
+			RatePricing{Rate: 10, HappyHours: []HappyHour{{Time{11, 0}, Time{13, 0}, 4}}},
+			Session{Table: 1, Start: Time{10, 30}, End: Time{13, 40}},
+			// 10:30 - 10, 11:30 - 4, 12:30 - 4, 13:30 - 10
+			28,
+		},
+		{
+			"Happy hours per minute",
+			RatePricing{Rate: 60, PerMinute: true, HappyHours: []HappyHour{{Time{11, 0}, Time{13, 0}, 0}}},
+			Session{Table: 1, Start: Time{10, 30}, End: Time{13, 10}},
+			40,
+		},
+		{"Daily cap", RatePricing{Rate: 10, DailyCap: 50}, Session{Table: 1, Start: Time{10, 0}, End: Time{13, 0}, Paid: 30}, 20},
+		{"Daily cap reached", RatePricing{Rate: 10, DailyCap: 50}, Session{Table: 1, Start: Time{10, 0}, End: Time{13, 0}, Paid: 60}, 0},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if charge := tc.policy.Charge(tc.session); charge != tc.charge {
+				t.Errorf("Invalid charge: %d != %d", charge, tc.charge)
+			}
+		})
+	}
+}
+
+func TestLoadPricingConfig(t *testing.T) {
+	config_str := `{
+		"table_rates": {"2": 15},
+		"per_minute": true,
+		"minimum": 5,
+		"happy_hours": [{"start": "14:00", "end": "16:00", "rate": 5}],
+		"daily_cap": 100
+	}`
+
+	config, err := LoadPricingConfig(strings.NewReader(config_str))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	policy, ok := config.Policy(10).(RatePricing)
+	if !ok {
+		t.Fatalf("Expected rate pricing")
+	}
+
+	if policy.Rate != 10 || policy.TableRates[2] != 15 || !policy.PerMinute || policy.Minimum != 5 || policy.DailyCap != 100 {
+		t.Errorf("Invalid policy: %+v", policy)
+	}
+
+	if len(policy.HappyHours) != 1 || policy.HappyHours[0].Start != (Time{14, 0}) {
+		t.Errorf("Invalid happy hours: %+v", policy.HappyHours)
+	}
+
+	invalid := []string{
+		`{"table_rates": {"0": 15}}`,
+		`{"happy_hours": [{"start": "14:00", "end": "14:00", "rate": 5}]}`,
+		`{"happy_hours": [{"start": "25:00", "end": "14:00", "rate": 5}]}`,
+		`{"unknown": 1}`,
+	}
+
+	for _, str := range invalid {
+		if _, err := LoadPricingConfig(strings.NewReader(str)); err == nil {
+			t.Errorf("Expected error for config: %s", str)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package pkg
+
+// Queue where elements of higher priority go first.
+// Elements of the same priority are kept in FIFO order
+type PriorityQueue[T any] struct {
+	levels []priorityLevel[T] // Sorted by priority from the highest
+	n      int                // Capacity or QUEUE_UNLIMITED
+	count  int
+}
+
+type priorityLevel[T any] struct {
+	priority int
+	queue    Queue[T]
+}
+
+func NewPriorityQueue[T any](n int) PriorityQueue[T] {
+	return PriorityQueue[T]{n: n}
+}
+
+func (q *PriorityQueue[T]) IsEmpty() bool {
+	return q.count == 0
+}
+
+func (q *PriorityQueue[T]) IsFull() bool {
+	return q.n != QUEUE_UNLIMITED && q.n <= q.count
+}
+
+// Number of elements in the queue
+func (q *PriorityQueue[T]) Len() int {
+	return q.count
+}
+
+// Number of elements which will go after element of the priority
+func (q *PriorityQueue[T]) LowerThan(priority int) int {
+	count := 0
+	for i := range q.levels {
+		if q.levels[i].priority < priority {
+			count += q.levels[i].queue.Len()
+		}
+	}
+	return count
+}
+
+func (q *PriorityQueue[T]) Push(val T, priority int) error {
+	if q.IsFull() {
+		return ErrQueueFull
+	}
+
+	q.ForcePush(val, priority)
+	return nil
+}
+
+// Pushes value even if queue is full
+func (q *PriorityQueue[T]) ForcePush(val T, priority int) {
+	q.level(priority).ForcePush(val)
+	q.count++
+}
+
+// Takes the first element of the highest priority
+func (q *PriorityQueue[T]) Pop() (T, error) {
+	for i := range q.levels {
+		if !q.levels[i].queue.IsEmpty() {
+			q.count--
+			return q.levels[i].queue.Pop()
+		}
+	}
+
+	var val T
+	return val, ErrQueueEmpty
+}
+
+// Takes the first element of the lowest priority
+func (q *PriorityQueue[T]) PopLowest() (T, error) {
+	for i := len(q.levels) - 1; 0 <= i; i-- {
+		if !q.levels[i].queue.IsEmpty() {
+			q.count--
+			return q.levels[i].queue.Pop()
+		}
+	}
+
+	var val T
+	return val, ErrQueueEmpty
+}
+
+// Priority of the elements which PopLowest takes
+func (q *PriorityQueue[T]) LowestPriority() (int, error) {
+	for i := len(q.levels) - 1; 0 <= i; i-- {
+		if !q.levels[i].queue.IsEmpty() {
+			return q.levels[i].priority, nil
+		}
+	}
+
+	return 0, ErrQueueEmpty
+}
+
+// Removes the first element which matches keeping order of others.
+// Returns false if there is no such element
+func (q *PriorityQueue[T]) Remove(match func(T) bool) bool {
+	for i := range q.levels {
+		if q.levels[i].queue.Remove(match) {
+			q.count--
+			return true
+		}
+	}
+
+	return false
+}
+
+// Elements of the queue in order they will be taken
+func (q *PriorityQueue[T]) Items() []T {
+	out := make([]T, 0, q.count)
+	for i := range q.levels {
+		out = append(out, q.levels[i].queue.Items()...)
+	}
+	return out
+}
+
+// Queue of the priority. Created if it does not exist
+func (q *PriorityQueue[T]) level(priority int) *Queue[T] {
+	idx := 0
+	for ; idx < len(q.levels); idx++ {
+		if q.levels[idx].priority == priority {
+			return &q.levels[idx].queue
+		}
+		if q.levels[idx].priority < priority {
+			break
+		}
+	}
+
+	q.levels = append(q.levels, priorityLevel[T]{})
+	copy(q.levels[idx+1:], q.levels[idx:])
+	q.levels[idx] = priorityLevel[T]{priority, NewQueue[T](QUEUE_UNLIMITED)}
+
+	return &q.levels[idx].queue
+}
+
+// Copy of the queue which does not share storage with original
+func (q *PriorityQueue[T]) Clone() PriorityQueue[T] {
+	out := *q
+	out.levels = make([]priorityLevel[T], 0, len(q.levels))
+	for i := range q.levels {
+		out.levels = append(out.levels, priorityLevel[T]{q.levels[i].priority, q.levels[i].queue.Clone()})
+	}
+	return out
+}
@@ -0,0 +1,89 @@
+package pkg
+
+import "testing"
+
+func TestPriorityQueueOrder(t *testing.T) {
+	q := NewPriorityQueue[string](QUEUE_UNLIMITED)
+
+	q.Push("a", 0)
+	q.Push("b", 0)
+	q.Push("vip1", 1)
+	q.Push("c", 0)
+	q.Push("vip2", 1)
+	q.Push("super", 2)
+
+	expected := []string{"super", "vip1", "vip2", "a", "b", "c"}
+
+	items := q.Items()
+	for i, v := range expected {
+		if items[i] != v {
+			t.Errorf("Invalid items order: %v", items)
+			break
+		}
+	}
+
+	for _, v := range expected {
+		val, err := q.Pop()
+		if err != nil {
+			t.Errorf("Failed to dequeue: %v", err)
+		}
+		if val != v {
+			t.Errorf("Invalid order: %s != %s", val, v)
+		}
+	}
+
+	if _, err := q.Pop(); err == nil {
+		t.Errorf("Expected underrun error")
+	}
+}
+
+func TestPriorityQueueCapacity(t *testing.T) {
+	q := NewPriorityQueue[string](2)
+
+	q.Push("a", 0)
+	if q.LowerThan(1) != 1 || q.LowerThan(0) != 0 {
+		t.Errorf("Invalid count of lower priority elements")
+	}
+
+	q.Push("vip", 1)
+	if !q.IsFull() {
+		t.Errorf("Expected queue to be full")
+	}
+
+	if err := q.Push("b", 0); err == nil {
+		t.Errorf("Expected overflow error")
+	}
+
+	q.ForcePush("b", 0)
+	if q.Len() != 3 {
+		t.Errorf("Expected queue over capacity")
+	}
+
+	if priority, _ := q.LowestPriority(); priority != 0 {
+		t.Errorf("Expected the lowest priority 0, got %d", priority)
+	}
+
+	if val, _ := q.PopLowest(); val != "a" {
+		t.Errorf("Expected the first of the lowest priority, got %s", val)
+	}
+}
+
+func TestPriorityQueueRemove(t *testing.T) {
+	q := NewPriorityQueue[string](3)
+	q.Push("a", 0)
+	q.Push("vip", 1)
+	q.Push("b", 0)
+
+	if !q.Remove(func(v string) bool { return v == "a" }) {
+		t.Fatalf("Element must be removed")
+	}
+
+	if q.Remove(func(v string) bool { return v == "c" }) {
+		t.Errorf("Unknown element must not be removed")
+	}
+
+	items := q.Items()
+	if q.Len() != 2 || q.IsFull() || len(items) != 2 || items[0] != "vip" || items[1] != "b" {
+		t.Errorf("Invalid items after remove: %v", items)
+	}
+}
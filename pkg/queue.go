@@ -7,59 +7,130 @@ var (
 	ErrQueueEmpty = errors.New("queue is empty")
 )
 
+// Capacity of the queue which grows without limit
+const QUEUE_UNLIMITED = -1
+
+// Start size of storage of unlimited queue
+const queue_initial_size = 8
+
 type Queue[T any] struct {
 	slice []T
-	n     int
-	start int
-	end   int
+	n     int // Capacity or QUEUE_UNLIMITED
+	start int // Index of the first element
+	count int
 }
 
 func NewQueue[T any](n int) Queue[T] {
+	size := n
+	if n == QUEUE_UNLIMITED {
+		size = queue_initial_size
+	}
 
 	return Queue[T]{
-		slice: make([]T, n),
+		slice: make([]T, size),
 		n:     n,
-		start: -1,
-		end:   -1,
 	}
 }
 
 func (q *Queue[T]) IsEmpty() bool {
-	return q.start == -1 && q.end == -1
+	return q.count == 0
 }
 
 func (q *Queue[T]) IsFull() bool {
-	return (q.end+1)%q.n == q.start
+	return q.n != QUEUE_UNLIMITED && q.n <= q.count
+}
+
+// Number of elements in the queue
+func (q *Queue[T]) Len() int {
+	return q.count
 }
 
 func (q *Queue[T]) Push(val T) error {
-	place_id := (q.end + 1) % q.n
 	if q.IsFull() {
 		return ErrQueueFull
 	}
 
-	if q.start == -1 {
-		q.start = 0
-	}
+	q.ForcePush(val)
+	return nil
+}
 
-	q.slice[place_id] = val
-	q.end = place_id
+// Pushes value even if queue is full
+func (q *Queue[T]) ForcePush(val T) {
+	if q.count == len(q.slice) {
+		q.grow()
+	}
 
-	return nil
+	q.slice[(q.start+q.count)%len(q.slice)] = val
+	q.count++
 }
 
 func (q *Queue[T]) Pop() (T, error) {
+	var val T
 	if q.IsEmpty() {
-		return q.slice[0], ErrQueueEmpty
+		return val, ErrQueueEmpty
 	}
 
-	val := q.slice[q.start]
+	val = q.slice[q.start]
 
-	if q.start == q.end {
-		q.start, q.end = -1, -1
-	} else {
-		q.start = (q.start + 1) % q.n
-	}
+	var zero T
+	q.slice[q.start] = zero
+	q.start = (q.start + 1) % len(q.slice)
+	q.count--
 
 	return val, nil
 }
+
+// Removes the first element which matches keeping order of others.
+// Returns false if there is no such element
+func (q *Queue[T]) Remove(match func(T) bool) bool {
+	for i := 0; i < q.count; i++ {
+		if !match(q.slice[(q.start+i)%len(q.slice)]) {
+			continue
+		}
+
+		for j := i; j < q.count-1; j++ {
+			q.slice[(q.start+j)%len(q.slice)] = q.slice[(q.start+j+1)%len(q.slice)]
+		}
+
+		var zero T
+		q.slice[(q.start+q.count-1)%len(q.slice)] = zero
+		q.count--
+		return true
+	}
+
+	return false
+}
+
+// Doubles size of the storage keeping order of the elements
+func (q *Queue[T]) grow() {
+	size := 2 * len(q.slice)
+	if size == 0 {
+		size = queue_initial_size
+	}
+
+	slice := make([]T, size)
+	for i := 0; i < q.count; i++ {
+		slice[i] = q.slice[(q.start+i)%len(q.slice)]
+	}
+
+	q.slice = slice
+	q.start = 0
+}
+
+// Elements of the queue from the first to the last
+func (q *Queue[T]) Items() []T {
+	out := make([]T, 0, q.count)
+
+	for i := 0; i < q.count; i++ {
+		out = append(out, q.slice[(q.start+i)%len(q.slice)])
+	}
+
+	return out
+}
+
+// Copy of the queue which does not share storage with original
+func (q *Queue[T]) Clone() Queue[T] {
+	out := *q
+	out.slice = append([]T(nil), q.slice...)
+	return out
+}
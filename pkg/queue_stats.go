@@ -0,0 +1,45 @@
+package pkg
+
+// Statistics of the waiting queue over the working day
+type QueueStats struct {
+	Waited         uint // Clients who waited in the queue
+	TotalWait      Duration
+	MaxWait        Duration
+	Walkaways      uint // Clients who left because the queue was full
+	WaitingAtClose uint
+	MaxLength      uint
+}
+
+// Average time in the queue of the clients who waited
+func (q QueueStats) AverageWait() Duration {
+	if q.Waited == 0 {
+		return 0
+	}
+	return q.TotalWait / Duration(q.Waited)
+}
+
+// Statistics of the waiting queue
+func (s State) QueueStats() QueueStats {
+	return s.queue_stats
+}
+
+func (q *QueueStats) recordWait(wait Duration) {
+	q.Waited++
+	q.TotalWait = q.TotalWait.Add(wait)
+	if q.MaxWait < wait {
+		q.MaxWait = wait
+	}
+}
+
+func (q *QueueStats) recordLength(length int) {
+	if q.MaxLength < uint(length) {
+		q.MaxLength = uint(length)
+	}
+}
+
+// Client leaves because the queue is full
+func (s *State) walkAway(client string) {
+	s.ClientLeave(client)
+	s.Emit(NewClientLeftOutputEvent(s.current_time, client))
+	s.queue_stats.Walkaways++
+}
@@ -72,3 +72,120 @@ func TestQueueUnderflow(t *testing.T) {
 	}
 
 }
+
+func TestQueueItems(t *testing.T) {
+	q := NewQueue[int](3)
+
+	if len(q.Items()) != 0 {
+		t.Errorf("Expected no items in empty queue")
+	}
+
+	// Move start to the middle of the slice
+	q.Push(0)
+	q.Push(1)
+	q.Pop()
+	q.Push(2)
+	q.Push(3)
+
+	items := q.Items()
+	if len(items) != 3 || items[0] != 1 || items[1] != 2 || items[2] != 3 {
+		t.Errorf("Invalid items: %v", items)
+	}
+}
+
+func TestQueueZeroCapacity(t *testing.T) {
+	q := NewQueue[int](0)
+
+	if !q.IsEmpty() || !q.IsFull() {
+		t.Errorf("Queue of zero capacity must be empty and full")
+	}
+
+	if err := q.Push(0); err == nil {
+		t.Errorf("Expected overflow error")
+	}
+}
+
+func TestQueueUnlimited(t *testing.T) {
+	count := 100
+	q := NewQueue[int](QUEUE_UNLIMITED)
+
+	// Shift start of the ring before growing
+	q.Push(-1)
+	q.Pop()
+
+	for i := 0; i < count; i++ {
+		if q.IsFull() {
+			t.Fatalf("Unlimited queue must never be full")
+		}
+		if err := q.Push(i); err != nil {
+			t.Errorf("Failed to insert in queue: %v", err)
+		}
+	}
+
+	if q.Len() != count {
+		t.Errorf("Invalid length: %d != %d", q.Len(), count)
+	}
+
+	for i := 0; i < count; i++ {
+		v, err := q.Pop()
+		if err != nil {
+			t.Errorf("Failed to dequeue: %v", err)
+		}
+
+		if v != i {
+			t.Errorf("Queue is not FIFO")
+		}
+	}
+}
+
+func TestQueueForcePush(t *testing.T) {
+	q := NewQueue[int](2)
+	q.Push(0)
+	q.Push(1)
+	q.ForcePush(2)
+
+	if !q.IsFull() || q.Len() != 3 {
+		t.Errorf("Expected queue over capacity")
+	}
+
+	items := q.Items()
+	if len(items) != 3 || items[0] != 0 || items[2] != 2 {
+		t.Errorf("Invalid items: %v", items)
+	}
+}
+
+func TestQueueRemove(t *testing.T) {
+	q := NewQueue[int](4)
+
+	// Elements wrap around the end of the ring
+	q.Push(-1)
+	q.Push(-2)
+	q.Pop()
+	q.Pop()
+	for i := 0; i < 4; i++ {
+		q.Push(i)
+	}
+
+	if !q.Remove(func(v int) bool { return v == 1 }) {
+		t.Fatalf("Element must be removed")
+	}
+
+	if q.Remove(func(v int) bool { return v == 1 }) {
+		t.Errorf("Removed element must not be found again")
+	}
+
+	items := q.Items()
+	if q.Len() != 3 || len(items) != 3 || items[0] != 0 || items[1] != 2 || items[2] != 3 {
+		t.Errorf("Invalid items after remove: %v", items)
+	}
+
+	if err := q.Push(4); err != nil {
+		t.Errorf("Removed element must free space: %v", err)
+	}
+
+	for _, expected := range []int{0, 2, 3, 4} {
+		if v, _ := q.Pop(); v != expected {
+			t.Errorf("Invalid order after remove: %d != %d", v, expected)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package pkg
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrDuplicateEventId = errors.New("event id is already registered")
+)
+
+// Parses and validates argument of input event which goes after client name
+type ArgParser func(value string, state State) (any, error)
+
+// Argument of input event
+type ArgSpec struct {
+	Field string // Name of the field reported in ParseError
+	Parse ArgParser
+}
+
+// Parsed arguments of input event in order of ArgSpec
+type Args []any
+
+func (a Args) Uint(i int) uint {
+	return a[i].(uint)
+}
+
+func (a Args) Time(i int) Time {
+	return a[i].(Time)
+}
+
+// Creates input event from parsed line
+type EventFactory func(time Time, client string, args Args) InputEvent
+
+// Makes factory from constructor of event which has no arguments
+func WithoutArgs(constructor func(time Time, client string) InputEvent) EventFactory {
+	return func(time Time, client string, _ Args) InputEvent {
+		return constructor(time, client)
+	}
+}
+
+// Type of input event
+type EventType struct {
+	Id   int
+	Args []ArgSpec
+	New  EventFactory
+}
+
+// Set of known event types. Input events are parsed using it
+type EventRegistry struct {
+	input  map[int]EventType
+	output map[int]struct{}
+}
+
+// Creates registry without any events
+func NewEventRegistry() *EventRegistry {
+	return &EventRegistry{
+		input:  make(map[int]EventType),
+		output: make(map[int]struct{}),
+	}
+}
+
+// Creates registry with events from the task
+func DefaultEventRegistry() *EventRegistry {
+	r := NewEventRegistry()
+
+	builtin := []EventType{
+		{EVENT_ID_IN_CLIENT_ENTERED, nil, WithoutArgs(NewClientEnteredInputEvent)},
+		{EVENT_ID_IN_CLIENT_TAKE_A_SEAT, []ArgSpec{{FIELD_TABLE, ParseTableArg}}, func(time Time, client string, args Args) InputEvent {
+			return NewClientTakeASeatInputEvent(time, client, args.Uint(0))
+		}},
+		{EVENT_ID_IN_CLIENT_CLIENT_WAITING, nil, WithoutArgs(NewClientWaitingInputEvent)},
+		{EVENT_ID_IN_CLIENT_LEFT, nil, WithoutArgs(NewClientLeftInputEvent)},
+		{EVENT_ID_IN_TABLE_RESERVED, []ArgSpec{{FIELD_TABLE, ParseTableArg}, {FIELD_START, ParseTimeArg}, {FIELD_END, ParseTimeArg}}, func(time Time, client string, args Args) InputEvent {
+			return NewTableReservedInputEvent(time, client, args.Uint(0), args.Time(1), args.Time(2))
+		}},
+	}
+
+	for _, t := range builtin {
+		if err := r.Register(t); err != nil {
+			panic(err)
+		}
+	}
+
+	builtin_output := []int{
+		EVENT_ID_OUT_CLIENT_LEFT,
+		EVENT_ID_OUT_CLIENT_TAKE_A_SEAT,
+		EVENT_ID_OUT_ERROR,
+		EVENT_ID_OUT_VIP_SKIPPED_QUEUE,
+		EVENT_ID_OUT_RESERVATION_FREED,
+		EVENT_ID_OUT_SESSION_EXPIRED,
+	}
+
+	for _, id := range builtin_output {
+		if err := r.RegisterOutput(id); err != nil {
+			panic(err)
+		}
+	}
+
+	return r
+}
+
+// Registry used by NewInputEvent
+var default_registry = DefaultEventRegistry()
+
+func (r *EventRegistry) used(id int) bool {
+	_, input := r.input[id]
+	_, output := r.output[id]
+	return id == EVENT_ID_UNKNOWN || input || output
+}
+
+// Adds type of input event. Id must not be used by other input or output event
+func (r *EventRegistry) Register(t EventType) error {
+	if r.used(t.Id) {
+		return ErrDuplicateEventId
+	}
+
+	r.input[t.Id] = t
+	return nil
+}
+
+// Reserves id for output event generated by custom input events
+func (r *EventRegistry) RegisterOutput(id int) error {
+	if r.used(id) {
+		return ErrDuplicateEventId
+	}
+
+	r.output[id] = struct{}{}
+	return nil
+}
+
+// Parses event from the line. Returns *ParseError on failure
+func (r *EventRegistry) Parse(description string, state State) (InputEvent, error) {
+	pieces := strings.Split(description, " ")
+
+	if len(pieces) < 3 {
+		// 3 pieces minimum: time, id, client
+		return nil, newFieldError(FIELD_ARGUMENTS, pieces, len(pieces), ErrInvalidEventFormat)
+	}
+
+	id, err := strconv.Atoi(pieces[1])
+	if err != nil {
+		return nil, newFieldError(FIELD_ID, pieces, 1, err)
+	}
+
+	time, err := MakeTime(pieces[0])
+	if err != nil {
+		return nil, newFieldError(FIELD_TIME, pieces, 0, err)
+	}
+
+	client := pieces[2]
+
+	if !ValidClientName(client) {
+		return nil, newFieldError(FIELD_CLIENT, pieces, 2, ErrInvalidEventFormat)
+	}
+
+	event_type, ok := r.input[id]
+	if !ok {
+		return nil, newFieldError(FIELD_ID, pieces, 1, ErrUnknownEventType)
+	}
+
+	remaining_pieces := pieces[3:]
+	if len(remaining_pieces) != len(event_type.Args) {
+		// Point to the first missing or extra argument
+		idx := 3 + len(remaining_pieces)
+		if len(event_type.Args) < len(remaining_pieces) {
+			idx = 3 + len(event_type.Args)
+		}
+		return nil, newFieldError(FIELD_ARGUMENTS, pieces, idx, ErrInvalidEventFormat)
+	}
+
+	args := make(Args, 0, len(event_type.Args))
+	for i, spec := range event_type.Args {
+		arg, err := spec.Parse(remaining_pieces[i], state)
+		if err != nil {
+			return nil, newFieldError(spec.Field, pieces, 3+i, err)
+		}
+		args = append(args, arg)
+	}
+
+	return event_type.New(time, client, args), nil
+}
+
+// Table number in range of the club tables
+func ParseTableArg(value string, state State) (any, error) {
+	table_nmb, err := strconv.ParseUint(value, 10, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if table_nmb == 0 || state.table_count < uint(table_nmb) {
+		return nil, ErrInvalidEventFormat
+	}
+
+	return uint(table_nmb), nil
+}
+
+// Time in HH:MM format
+func ParseTimeArg(value string, state State) (any, error) {
+	return MakeTime(value)
+}
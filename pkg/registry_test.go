@@ -0,0 +1,158 @@
+package pkg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const (
+	testEventIdPing    = 105
+	testEventIdPingOut = 115
+)
+
+// Custom event which uses only exported API as it is done outside of the package
+type pingInputEvent struct {
+	ClientAssociatedEvent
+	table uint
+	until Time
+}
+
+func (e *pingInputEvent) String() string {
+	return fmt.Sprintf("%s %d %v", e.ClientAssociatedEvent.String(), e.table, e.until)
+}
+
+func (e *pingInputEvent) Translate(s *State) {
+	if !s.Known(e.Client()) {
+		s.Emit(NewErrorOutputEvent(e, MSG_CLIENT_UNKNOWN))
+		return
+	}
+
+	s.Emit(MakeClientAssociatedEvent(testEventIdPingOut, s.CurrentTime(), e.Client()))
+}
+
+func newTestRegistry(t *testing.T) *EventRegistry {
+	registry := DefaultEventRegistry()
+
+	ping := EventType{
+		Id: testEventIdPing,
+		Args: []ArgSpec{
+			{FIELD_TABLE, ParseTableArg},
+			{"until", ParseTimeArg},
+		},
+		New: func(time Time, client string, args Args) InputEvent {
+			return &pingInputEvent{MakeClientAssociatedEvent(testEventIdPing, time, client), args.Uint(0), args.Time(1)}
+		},
+	}
+
+	if err := registry.Register(ping); err != nil {
+		t.Fatalf("Failed to register event: %v", err)
+	}
+
+	if err := registry.RegisterOutput(testEventIdPingOut); err != nil {
+		t.Fatalf("Failed to register output event: %v", err)
+	}
+
+	return registry
+}
+
+func TestRegistryDuplicates(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	ids := []int{
+		EVENT_ID_UNKNOWN,
+		EVENT_ID_IN_CLIENT_ENTERED,
+		EVENT_ID_OUT_ERROR,
+		testEventIdPing,
+		testEventIdPingOut,
+	}
+
+	for _, id := range ids {
+		if err := registry.Register(EventType{Id: id, New: WithoutArgs(NewClientEnteredInputEvent)}); !errors.Is(err, ErrDuplicateEventId) {
+			t.Errorf("Expected duplicate error for input id %d", id)
+		}
+
+		if err := registry.RegisterOutput(id); !errors.Is(err, ErrDuplicateEventId) {
+			t.Errorf("Expected duplicate error for output id %d", id)
+		}
+	}
+}
+
+func TestRegistryArguments(t *testing.T) {
+	registry := newTestRegistry(t)
+	state := MakeState()
+	state.InitTables(2)
+
+	test_cases := []struct {
+		line   string
+		field  string
+		column int
+	}{
+		{"10:00 105 a 1", FIELD_ARGUMENTS, 15},
+		{"10:00 105 a 1 11:00 2", FIELD_ARGUMENTS, 21},
+		{"10:00 105 a 3 11:00", FIELD_TABLE, 13},
+		{"10:00 105 a 1 1100", "until", 15},
+		{"10:00 1 a 1", FIELD_ARGUMENTS, 11},
+	}
+
+	for _, tc := range test_cases {
+		_, err := registry.Parse(tc.line, state)
+
+		var parse_err *ParseError
+		if !errors.As(err, &parse_err) {
+			t.Errorf("Expected parse error for '%s', got %v", tc.line, err)
+			continue
+		}
+
+		if parse_err.Field != tc.field || parse_err.Column != tc.column {
+			t.Errorf("Invalid error for '%s': %v", tc.line, parse_err)
+		}
+	}
+
+	event, err := registry.Parse("10:00 105 a 2 11:30", state)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ping, ok := event.(*pingInputEvent)
+	if !ok || ping.table != 2 || ping.until != (Time{11, 30}) {
+		t.Errorf("Invalid event parsed: %v", event)
+	}
+
+	if _, err := NewInputEvent("10:00 105 a 2 11:30", state); !errors.Is(err, ErrUnknownEventType) {
+		t.Errorf("Custom event must not be known by default registry")
+	}
+}
+
+func TestAppCustomEvent(t *testing.T) {
+	input := strings.Join([]string{
+		"1",
+		"09:00 19:00",
+		"10",
+		"09:10 105 a 1 10:00",
+		"09:20 1 a",
+		"09:30 105 a 1 10:00",
+	}, "\n")
+
+	real_output := bytes.NewBufferString("")
+	app := NewApp(strings.NewReader(input), real_output)
+	app.SetEventRegistry(newTestRegistry(t))
+
+	if err := app.Process(); err != nil {
+		t.Fatalf("app process error: %v", err)
+	}
+
+	expected := strings.Join([]string{
+		"09:10 105 a 1 10:00",
+		"09:10 13 ClientUnknown",
+		"09:20 1 a",
+		"09:30 105 a 1 10:00",
+		"09:30 115 a",
+	}, "\n") + "\n"
+
+	if !strings.Contains(real_output.String(), expected) {
+		t.Errorf("Expected events:\n%s\nReal output:\n%s", expected, real_output.String())
+	}
+}
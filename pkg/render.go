@@ -0,0 +1,478 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Headers of additional sections in text output
+const (
+	TOTAL_HEADER    = "TOTAL"
+	CLIENTS_HEADER  = "CLIENTS"
+	STATS_HEADER    = "STATS"
+	QUEUE_HEADER    = "QUEUE"
+	TIMELINE_HEADER = "TIMELINE"
+	HOURLY_HEADER   = "HOURLY"
+)
+
+// Renderer writes results of App processing to output
+type Renderer interface {
+	// Write the line which stopped processing
+	RenderError(w io.Writer, line string, err error) error
+
+	// Write the transcript of processed state
+	RenderResult(w io.Writer, s *State) error
+
+	// Write sum of all days in multi-day log
+	RenderTotal(w io.Writer, totals Totals) error
+
+	// Write errors collected in lenient mode
+	RenderDiagnostics(w io.Writer, errs ParseErrors) error
+}
+
+// Renderer which can write the transcript while events are processed.
+// Output of the parts together must be the same as of RenderResult
+type StreamRenderer interface {
+	Renderer
+
+	// Write everything before the events of the working day
+	RenderDayStart(w io.Writer, s *State) error
+
+	// Write events as soon as they are processed
+	RenderEvents(w io.Writer, events []Event) error
+
+	// Write everything after the events of the working day
+	RenderDayEnd(w io.Writer, s *State) error
+}
+
+// Plain text output as described in the task
+type TextRenderer struct {
+	// Add bills of the clients after tables summary
+	Clients bool
+
+	// Add statistics of the tables after tables summary
+	Stats bool
+
+	// Add statistics of the waiting queue after tables summary
+	Queue bool
+
+	// Add chart of the tables occupation and hourly load after tables summary
+	Timeline bool
+}
+
+func (TextRenderer) RenderError(w io.Writer, line string, err error) error {
+	_, werr := fmt.Fprintln(w, line)
+	return werr
+}
+
+func (r TextRenderer) RenderResult(w io.Writer, s *State) error {
+	if err := r.RenderDayStart(w, s); err != nil {
+		return err
+	}
+
+	if err := r.RenderEvents(w, s.events); err != nil {
+		return err
+	}
+
+	return r.RenderDayEnd(w, s)
+}
+
+func (TextRenderer) RenderDayStart(w io.Writer, s *State) error {
+	if len(s.date) != 0 {
+		fmt.Fprintln(w, s.date)
+	}
+
+	_, err := fmt.Fprintln(w, s.time_start)
+	return err
+}
+
+func (TextRenderer) RenderEvents(w io.Writer, events []Event) error {
+	for _, e := range events {
+		if _, err := fmt.Fprintln(w, e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r TextRenderer) RenderDayEnd(w io.Writer, s *State) error {
+	fmt.Fprintln(w, s.time_end)
+
+	for i := uint(0); i < s.table_count; i++ {
+		if _, err := fmt.Fprintf(w, "%d %d %v\n", i+1, s.tables_profit[i], s.tables_usage[i]); err != nil {
+			return err
+		}
+	}
+
+	if r.Stats {
+		if err := r.renderStats(w, s); err != nil {
+			return err
+		}
+	}
+
+	if r.Queue {
+		if err := r.renderQueue(w, s); err != nil {
+			return err
+		}
+	}
+
+	if r.Timeline {
+		if err := r.renderTimeline(w, s); err != nil {
+			return err
+		}
+	}
+
+	if r.Clients {
+		return r.renderClients(w, s)
+	}
+
+	return nil
+}
+
+// Chart with a row for every table where every cell is TIMELINE_CELL_MINUTES
+// followed by symbols of the clients and a line for every hour as
+// "hour |busy tables| number |waiting clients| number"
+func (TextRenderer) renderTimeline(w io.Writer, s *State) error {
+	fmt.Fprintln(w, TIMELINE_HEADER)
+
+	width := len(strconv.FormatUint(uint64(s.table_count), 10))
+	cells_in_hour := MINUTES_IN_HOUR / TIMELINE_CELL_MINUTES
+
+	axis := make([]string, 0)
+	for _, load := range s.HourlyLoad() {
+		axis = append(axis, fmt.Sprintf("%-*s", cells_in_hour, fmt.Sprintf("%02d", load.Hour.Hour)))
+	}
+	fmt.Fprintf(w, "%*s |%s|\n", width, "", strings.Join(axis, ""))
+
+	for i, row := range s.timelineRows() {
+		fmt.Fprintf(w, "%*d |%s|\n", width, i+1, row)
+	}
+
+	symbols, clients := s.timelineSymbols()
+	for _, client := range clients {
+		fmt.Fprintf(w, "%c %s\n", symbols[client], client)
+	}
+
+	fmt.Fprintln(w, HOURLY_HEADER)
+
+	hourly := s.HourlyLoad()
+	longest := uint(0)
+	for _, load := range hourly {
+		if longest < load.Queue {
+			longest = load.Queue
+		}
+	}
+
+	for _, load := range hourly {
+		tables := strings.Repeat("#", int(load.Tables)) + strings.Repeat(".", int(s.table_count-load.Tables))
+		queue := strings.Repeat("#", int(load.Queue)) + strings.Repeat(" ", int(longest-load.Queue))
+		if _, err := fmt.Fprintf(w, "%v |%s| %d |%s| %d\n", load.Hour, tables, load.Tables, queue, load.Queue); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Waiting queue as "waited average_wait max_wait walkaways waiting_at_close max_length"
+func (TextRenderer) renderQueue(w io.Writer, s *State) error {
+	fmt.Fprintln(w, QUEUE_HEADER)
+
+	q := s.QueueStats()
+	_, err := fmt.Fprintf(w, "%d %v %v %d %d %d\n", q.Waited, q.AverageWait(), q.MaxWait, q.Walkaways, q.WaitingAtClose, q.MaxLength)
+	return err
+}
+
+// Every table as "table sessions average longest idle utilization busiest_hour"
+func (TextRenderer) renderStats(w io.Writer, s *State) error {
+	fmt.Fprintln(w, STATS_HEADER)
+
+	for _, stats := range s.TableStats() {
+		busiest := "-"
+		if stats.BusiestHour != nil {
+			busiest = stats.BusiestHour.String()
+		}
+
+		if _, err := fmt.Fprintf(w, "%d %d %v %v %v %d%% %s\n", stats.Table, stats.Sessions, stats.Average, stats.Longest, stats.Idle, stats.Utilization, busiest); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Every visit as "client arrived left waiting hours amount"
+// followed by its sessions as "table start end hours amount"
+func (TextRenderer) renderClients(w io.Writer, s *State) error {
+	fmt.Fprintln(w, CLIENTS_HEADER)
+
+	for _, v := range s.visits {
+		fmt.Fprintf(w, "%s %v %v %v %d %d\n", v.Client, v.Arrived, v.Left, v.Waiting, v.Hours(), v.Amount())
+
+		for _, session := range v.Sessions {
+			if _, err := fmt.Fprintf(w, "\t%d %v %v %d %d\n", session.Table, session.Start, session.End, session.Hours, session.Amount); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (TextRenderer) RenderTotal(w io.Writer, totals Totals) error {
+	fmt.Fprintln(w, TOTAL_HEADER)
+
+	for i := range totals.Profit {
+		if _, err := fmt.Fprintf(w, "%d %d %v\n", i+1, totals.Profit[i], totals.Usage[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (TextRenderer) RenderDiagnostics(w io.Writer, errs ParseErrors) error {
+	for _, e := range errs {
+		// Point to the failed field below the line
+		caret := strings.Repeat(" ", e.Column-1) + "^"
+		if _, err := fmt.Fprintf(w, "%v\n\t%s\n\t%s\n", e, e.Text, caret); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// JSON output for machine processing
+type JSONRenderer struct {
+	// Indent output for humans
+	Pretty bool
+
+	// Add bills of the clients
+	Clients bool
+
+	// Add statistics of the tables
+	Stats bool
+
+	// Add statistics of the waiting queue
+	Queue bool
+
+	// Add occupation of the tables and hourly load
+	Timeline bool
+}
+
+type jsonClub struct {
+	Tables uint `json:"tables"`
+	Open   Time `json:"open"`
+	Close  Time `json:"close"`
+	Price  uint `json:"price"`
+}
+
+type jsonEvent struct {
+	Id      int    `json:"id"`
+	Time    Time   `json:"time"`
+	Client  string `json:"client,omitempty"`
+	Table   uint   `json:"table,omitempty"`
+	Skipped uint   `json:"skipped,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type jsonTable struct {
+	Table  uint     `json:"table"`
+	Profit uint     `json:"profit"`
+	Usage  Duration `json:"usage"`
+}
+
+type jsonTableStats struct {
+	Table       uint     `json:"table"`
+	Sessions    uint     `json:"sessions"`
+	Average     Duration `json:"average"`
+	Longest     Duration `json:"longest"`
+	Idle        Duration `json:"idle"`
+	Utilization uint     `json:"utilization"`
+	BusiestHour *Time    `json:"busiest_hour,omitempty"`
+}
+
+type jsonQueueStats struct {
+	Waited         uint     `json:"waited"`
+	AverageWait    Duration `json:"average_wait"`
+	MaxWait        Duration `json:"max_wait"`
+	Walkaways      uint     `json:"walkaways"`
+	WaitingAtClose uint     `json:"waiting_at_close"`
+	MaxLength      uint     `json:"max_length"`
+}
+
+type jsonOccupation struct {
+	Table  uint   `json:"table"`
+	Client string `json:"client"`
+	Start  Time   `json:"start"`
+	End    Time   `json:"end"`
+}
+
+type jsonHourlyLoad struct {
+	Hour   Time `json:"hour"`
+	Tables uint `json:"tables"`
+	Queue  uint `json:"queue"`
+}
+
+type jsonSession struct {
+	Table  uint `json:"table"`
+	Start  Time `json:"start"`
+	End    Time `json:"end"`
+	Hours  uint `json:"hours"`
+	Amount uint `json:"amount"`
+}
+
+type jsonVisit struct {
+	Client   string        `json:"client"`
+	Arrived  Time          `json:"arrived"`
+	Left     Time          `json:"left"`
+	Waiting  Duration      `json:"waiting"`
+	Hours    uint          `json:"hours"`
+	Amount   uint          `json:"amount"`
+	Sessions []jsonSession `json:"sessions"`
+}
+
+type jsonResult struct {
+	Date   Date        `json:"date,omitempty"`
+	Club   jsonClub    `json:"club"`
+	Events []jsonEvent `json:"events"`
+	Tables []jsonTable `json:"tables"`
+
+	Stats []jsonTableStats `json:"stats,omitempty"`
+	Queue *jsonQueueStats  `json:"queue,omitempty"`
+
+	Timeline []jsonOccupation `json:"timeline,omitempty"`
+	Hourly   []jsonHourlyLoad `json:"hourly,omitempty"`
+
+	Clients []jsonVisit `json:"clients,omitempty"`
+}
+
+type jsonTotal struct {
+	Total []jsonTable `json:"total"`
+}
+
+type jsonError struct {
+	Error string `json:"error"`
+	Line  string `json:"line"`
+}
+
+type jsonDiagnostic struct {
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+	Text   string `json:"text"`
+}
+
+type jsonDiagnostics struct {
+	Diagnostics []jsonDiagnostic `json:"diagnostics"`
+}
+
+// Converts event to its JSON representation using optional accessors
+func makeJSONEvent(e Event) jsonEvent {
+	out := jsonEvent{Id: e.Id(), Time: e.Time()}
+
+	if ce, ok := e.(interface{ Client() string }); ok {
+		out.Client = ce.Client()
+	}
+	if te, ok := e.(interface{ Table() uint }); ok {
+		out.Table = te.Table()
+	}
+	if se, ok := e.(interface{ Skipped() uint }); ok {
+		out.Skipped = se.Skipped()
+	}
+	if me, ok := e.(interface{ Message() string }); ok {
+		out.Message = me.Message()
+	}
+
+	return out
+}
+
+func (r JSONRenderer) encode(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	if r.Pretty {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder.Encode(v)
+}
+
+func (r JSONRenderer) RenderError(w io.Writer, line string, err error) error {
+	return r.encode(w, jsonError{err.Error(), line})
+}
+
+func (r JSONRenderer) RenderResult(w io.Writer, s *State) error {
+	result := jsonResult{
+		Date:   s.date,
+		Club:   jsonClub{s.table_count, s.time_start, s.time_end, s.price},
+		Events: make([]jsonEvent, 0, len(s.events)),
+		Tables: make([]jsonTable, 0, s.table_count),
+	}
+
+	for _, e := range s.events {
+		result.Events = append(result.Events, makeJSONEvent(e))
+	}
+
+	for i := uint(0); i < s.table_count; i++ {
+		result.Tables = append(result.Tables, jsonTable{i + 1, s.tables_profit[i], s.tables_usage[i]})
+	}
+
+	if r.Stats {
+		for _, stats := range s.TableStats() {
+			result.Stats = append(result.Stats, jsonTableStats{stats.Table, stats.Sessions, stats.Average, stats.Longest, stats.Idle, stats.Utilization, stats.BusiestHour})
+		}
+	}
+
+	if r.Queue {
+		q := s.QueueStats()
+		result.Queue = &jsonQueueStats{q.Waited, q.AverageWait(), q.MaxWait, q.Walkaways, q.WaitingAtClose, q.MaxLength}
+	}
+
+	if r.Timeline {
+		for _, o := range s.Occupations() {
+			result.Timeline = append(result.Timeline, jsonOccupation(o))
+		}
+		for _, load := range s.HourlyLoad() {
+			result.Hourly = append(result.Hourly, jsonHourlyLoad(load))
+		}
+	}
+
+	if r.Clients {
+		result.Clients = make([]jsonVisit, 0, len(s.visits))
+		for _, v := range s.visits {
+			visit := jsonVisit{v.Client, v.Arrived, v.Left, v.Waiting, v.Hours(), v.Amount(), make([]jsonSession, 0, len(v.Sessions))}
+			for _, session := range v.Sessions {
+				visit.Sessions = append(visit.Sessions, jsonSession(session))
+			}
+			result.Clients = append(result.Clients, visit)
+		}
+	}
+
+	return r.encode(w, result)
+}
+
+func (r JSONRenderer) RenderTotal(w io.Writer, totals Totals) error {
+	out := jsonTotal{make([]jsonTable, 0, len(totals.Profit))}
+
+	for i := range totals.Profit {
+		out.Total = append(out.Total, jsonTable{uint(i + 1), totals.Profit[i], totals.Usage[i]})
+	}
+
+	return r.encode(w, out)
+}
+
+func (r JSONRenderer) RenderDiagnostics(w io.Writer, errs ParseErrors) error {
+	return r.encode(w, jsonDiagnostics{makeJSONDiagnostics(errs)})
+}
+
+func makeJSONDiagnostics(errs ParseErrors) []jsonDiagnostic {
+	out := make([]jsonDiagnostic, 0, len(errs))
+	for _, e := range errs {
+		out = append(out, jsonDiagnostic{e.Line, e.Column, e.Field, e.Err.Error(), e.Text})
+	}
+	return out
+}
@@ -0,0 +1,192 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Commands of interactive mode
+const (
+	REPL_STATUS = "status"
+	REPL_QUEUE  = "queue"
+	REPL_TABLES = "tables"
+	REPL_UNDO   = "undo"
+	REPL_CLOSE  = "close"
+	REPL_HELP   = "help"
+)
+
+var (
+	ErrNothingToUndo  = errors.New("nothing to undo")
+	ErrUnknownCommand = errors.New("unknown command, type help")
+)
+
+// Interactive mode: header of the club is read first, then events are applied
+// one by one and generated events are printed immediately
+type Repl struct {
+	app     App
+	history []State // States before applied events, the last is the newest
+
+	// Printed before every line after the header
+	Prompt string
+}
+
+// Creates interactive mode which reads and writes using the app.
+// Settings of the app such as pricing or queue are kept
+func NewRepl(app App) *Repl {
+	return &Repl{app: app}
+}
+
+// Reads lines until close command or end of input.
+// Results of the working day are printed at the end
+func (r *Repl) Run() error {
+	if err := r.app.readClubInfo(); err != nil {
+		return err
+	}
+
+	for r.prompt(); r.app.scan(); r.prompt() {
+		line := strings.TrimSpace(r.app.input.Text())
+		if len(line) == 0 {
+			continue
+		}
+
+		if line == REPL_CLOSE {
+			break
+		}
+
+		if err := r.execute(line); err != nil {
+			fmt.Fprintf(r.app.output, "error: %v\n", err)
+		}
+	}
+
+	if err := r.app.input.Err(); err != nil {
+		return err
+	}
+
+	if err := r.app.closeDay(); err != nil {
+		return err
+	}
+
+	return r.app.writeResults()
+}
+
+func (r *Repl) prompt() {
+	if len(r.Prompt) != 0 {
+		fmt.Fprint(r.app.output, r.Prompt)
+	}
+}
+
+// Runs command or applies event from the line
+func (r *Repl) execute(line string) error {
+	switch line {
+	case REPL_STATUS:
+		return r.status()
+	case REPL_QUEUE:
+		return r.queue()
+	case REPL_TABLES:
+		return r.tables()
+	case REPL_UNDO:
+		return r.undo()
+	case REPL_HELP:
+		return r.help()
+	}
+
+	if !strings.Contains(line, " ") {
+		return ErrUnknownCommand
+	}
+
+	return r.apply(line)
+}
+
+func (r *Repl) apply(line string) error {
+	s := &r.app.state
+
+	event, err := r.app.registry.Parse(line, *s)
+	if err != nil {
+		return err
+	}
+
+	before := s.Clone()
+	generated, err := s.Apply(event)
+	if err != nil {
+		return err
+	}
+	r.history = append(r.history, before)
+
+	for _, e := range generated {
+		if _, err := fmt.Fprintln(r.app.output, e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Time, number of clients, busy tables, waiting clients and revenue
+func (r *Repl) status() error {
+	s := &r.app.state
+
+	busy := 0
+	revenue := uint(0)
+	for i := uint(0); i < s.table_count; i++ {
+		if s.TableBusy(i + 1) {
+			busy++
+		}
+		revenue += s.tables_profit[i]
+	}
+
+	_, err := fmt.Fprintf(r.app.output, "time %v clients %d tables %d/%d queue %d revenue %d\n",
+		s.current_time, len(s.client_set), busy, s.table_count, s.queue.Len(), revenue)
+	return err
+}
+
+// Waiting clients in order they will be seated
+func (r *Repl) queue() error {
+	for _, client := range r.app.state.queue.Items() {
+		if _, err := fmt.Fprintln(r.app.output, client); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Every table as "table profit usage", busy tables are followed by client
+// and time when it took the table
+func (r *Repl) tables() error {
+	s := &r.app.state
+
+	for i := uint(0); i < s.table_count; i++ {
+		line := fmt.Sprintf("%d %d %v", i+1, s.tables_profit[i], s.tables_usage[i])
+		if s.TableBusy(i + 1) {
+			line += fmt.Sprintf(" %s %v", s.tables_occupation[i], s.tables_start_time[i])
+		}
+
+		if _, err := fmt.Fprintln(r.app.output, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reverts the last applied event with events generated by it
+func (r *Repl) undo() error {
+	if len(r.history) == 0 {
+		return ErrNothingToUndo
+	}
+
+	last := len(r.history) - 1
+	r.app.state = r.history[last]
+	r.history = r.history[:last]
+	return nil
+}
+
+func (r *Repl) help() error {
+	_, err := fmt.Fprintf(r.app.output, "<time> <id> <client> [args] - apply event\n"+
+		"%s - current time, clients, tables, queue and revenue\n"+
+		"%s - waiting clients\n"+
+		"%s - occupation and revenue of the tables\n"+
+		"%s - revert the last event\n"+
+		"%s - close the club and print results\n",
+		REPL_STATUS, REPL_QUEUE, REPL_TABLES, REPL_UNDO, REPL_CLOSE)
+	return err
+}
@@ -0,0 +1,81 @@
+package pkg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRepl(t *testing.T) {
+	input := strings.Join([]string{
+		"1",
+		"09:00 19:00",
+		"10",
+		"09:10 1 a",
+		"09:10 2 a 1",
+		"09:20 1 b",
+		"09:20 3 b",
+		"queue",
+		"10:00 4 a",
+		"tables",
+		"undo",
+		"status",
+		"tables",
+		"08:00 1 c",
+		"hello",
+		"undo",
+		"undo",
+		"undo",
+		"undo",
+		"undo",
+		"close",
+		"09:30 1 ignored",
+	}, "\n")
+
+	real_output := bytes.NewBufferString("")
+	repl := NewRepl(NewApp(strings.NewReader(input), real_output))
+
+	if err := repl.Run(); err != nil {
+		t.Fatalf("repl error: %v", err)
+	}
+
+	expected := strings.Join([]string{
+		"b",
+		"10:00 12 b 1",
+		"1 10 00:50 b 10:00",
+		"time 09:20 clients 2 tables 1/1 queue 1 revenue 0",
+		"1 0 00:00 a 09:10",
+		"error: invalid order of events",
+		"error: unknown command, type help",
+		"error: nothing to undo",
+		"09:00",
+		"19:00",
+		"1 0 00:00",
+	}, "\n") + "\n"
+
+	if real_output.String() != expected {
+		t.Errorf("Expected:\n%s\nReal output:\n%s", expected, real_output.String())
+	}
+}
+
+func TestReplPrompt(t *testing.T) {
+	input := strings.Join([]string{
+		"1",
+		"09:00 19:00",
+		"10",
+		"09:10 1 a",
+	}, "\n")
+
+	real_output := bytes.NewBufferString("")
+	repl := NewRepl(NewApp(strings.NewReader(input), real_output))
+	repl.Prompt = "> "
+
+	if err := repl.Run(); err != nil {
+		t.Fatalf("repl error: %v", err)
+	}
+
+	expected := "> > 09:00\n09:10 1 a\n19:00 11 a\n19:00\n1 0 00:00\n"
+	if real_output.String() != expected {
+		t.Errorf("Expected:\n%q\nReal output:\n%q", expected, real_output.String())
+	}
+}
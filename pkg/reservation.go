@@ -0,0 +1,107 @@
+package pkg
+
+// Minutes the table waits for the reserved client by default
+const RESERVATION_GRACE_DEFAULT = 15
+
+// Table kept for the client in the window of time
+type Reservation struct {
+	Client string
+	Table  uint
+	Start  Time
+	End    Time
+
+	seated bool // Client came, so the reservation will not be released
+}
+
+// Time when table is released if client has not come
+func (s State) releaseTime(r *Reservation) Time {
+	day := s.WorkDay()
+
+	deadline := TimeFromMinutes(r.Start.InMinutes() + s.reservation_grace)
+	if day.Less(r.End, deadline) {
+		deadline = r.End
+	}
+	return deadline
+}
+
+// Reservation of the table at the time
+func (s State) activeReservation(table uint, t Time) *Reservation {
+	day := s.WorkDay()
+	for _, r := range s.reservations {
+		if r.Table == table && day.LessOrEquals(r.Start, t) && day.Less(t, r.End) {
+			return r
+		}
+	}
+	return nil
+}
+
+// Is the table kept for other client at the current time
+func (s State) ReservedForOther(table uint, client string) bool {
+	r := s.activeReservation(table, s.current_time)
+	return r != nil && r.Client != client
+}
+
+// Adds reservation if it does not overlap with others for the table
+func (s *State) Reserve(r Reservation) bool {
+	day := s.WorkDay()
+	for _, other := range s.reservations {
+		if other.Table == r.Table && day.Less(other.Start, r.End) && day.Less(r.Start, other.End) {
+			return false
+		}
+	}
+
+	s.reservations = append(s.reservations, &r)
+	s.schedule(timer{at: s.releaseTime(&r), kind: TIMER_RESERVATION_RELEASE, client: r.Client, table: r.Table, since: r.Start})
+	return true
+}
+
+// Marks reservations of the client for the table as used
+func (s *State) markSeated(table uint, client string) {
+	day := s.WorkDay()
+	for _, r := range s.reservations {
+		if r.Table == table && r.Client == client && day.Less(s.current_time, r.End) {
+			r.seated = true
+		}
+	}
+}
+
+// Client has left the table, so its reservation in progress is used up and
+// the table may be given to others. Reservations which have not begun yet
+// wait for the client again
+func (s *State) leaveReservations(table uint, client string) {
+	day := s.WorkDay()
+
+	rest := make([]*Reservation, 0, len(s.reservations))
+	for _, r := range s.reservations {
+		if r.Table == table && r.Client == client && r.seated {
+			if day.LessOrEquals(r.Start, s.current_time) {
+				continue
+			}
+			r.seated = false
+		}
+		rest = append(rest, r)
+	}
+
+	s.reservations = rest
+}
+
+// Releases the table if the client has not come. Client may still come at
+// the last minute of grace period. Waiting client takes released table
+func (s *State) releaseReservation(t timer) {
+	for i, r := range s.reservations {
+		if r.Table != t.table || r.Start != t.since {
+			continue
+		}
+
+		if r.seated {
+			return
+		}
+
+		s.reservations = append(s.reservations[:i], s.reservations[i+1:]...)
+		s.current_time = t.at
+		s.Emit(NewReservationReleasedOutputEvent(s.current_time, r.Client, r.Table))
+
+		s.SeatFromQueue(r.Table)
+		return
+	}
+}
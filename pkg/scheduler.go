@@ -0,0 +1,83 @@
+package pkg
+
+import "sort"
+
+// Kinds of timers. Timers of the same minute fire in this order
+type timerKind int
+
+const (
+	TIMER_SESSION_END         timerKind = iota // Client has reached the session limit
+	TIMER_RESERVATION_RELEASE                  // Reserved client has not come in time
+	TIMER_WAIT_TIMEOUT                         // Client has waited in the queue too long
+	TIMER_CLUB_CLOSE
+)
+
+// Generated event scheduled at the time of the working day. Timer is stale and
+// does nothing if the state has changed since it was registered
+type timer struct {
+	at     Time
+	kind   timerKind
+	client string
+	table  uint
+	since  Time // Beginning of the session, the waiting or the reservation
+}
+
+// Registers timer. Timer in the past fires at the current time,
+// so generated events are never written before events already processed
+func (s *State) schedule(t timer) {
+	day := s.WorkDay()
+	if day.Less(t.at, s.current_time) {
+		t.at = s.current_time
+	}
+
+	// Timers of the same minute and kind fire in order of registration
+	after := func(other timer) bool {
+		if day.Offset(other.at) != day.Offset(t.at) {
+			return day.Offset(t.at) < day.Offset(other.at)
+		}
+		return t.kind < other.kind
+	}
+	i := sort.Search(len(s.timers), func(i int) bool { return after(s.timers[i]) })
+
+	s.timers = append(s.timers, timer{})
+	copy(s.timers[i+1:], s.timers[i:])
+	s.timers[i] = t
+}
+
+// Registers timer minutes after its beginning. Timers after close of the club
+// are dropped, since everybody leaves at close
+func (s *State) scheduleAfter(t timer, minutes int) {
+	day := s.WorkDay()
+	if day.Offset(s.time_end) < day.Offset(t.since)+minutes {
+		return
+	}
+
+	t.at = TimeFromMinutes(t.since.InMinutes() + minutes)
+	s.schedule(t)
+}
+
+// Fires timers before the time. Timers of the time itself fire after input
+// events of the same minute
+func (s *State) runTimers(until Time) {
+	day := s.WorkDay()
+	for len(s.timers) != 0 && day.Less(s.timers[0].at, until) {
+		s.fire()
+	}
+}
+
+// Fires the first timer
+func (s *State) fire() {
+	t := s.timers[0]
+	s.timers = s.timers[1:]
+
+	switch t.kind {
+	case TIMER_SESSION_END:
+		s.endSession(t)
+	case TIMER_RESERVATION_RELEASE:
+		s.releaseReservation(t)
+	case TIMER_WAIT_TIMEOUT:
+		s.timeoutWaiting(t)
+	case TIMER_CLUB_CLOSE:
+		s.closeClub()
+	}
+}
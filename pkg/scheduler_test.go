@@ -0,0 +1,102 @@
+package pkg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAppTimersOrder(t *testing.T) {
+	input := strings.Join([]string{
+		"2",
+		"09:00 19:00",
+		"10",
+		"09:00 5 r 2 09:00 12:00",
+		"09:05 1 a",
+		"09:05 2 a 1",
+		"09:10 1 b",
+		"09:10 3 b",
+		"09:12 1 c",
+		"09:12 3 c",
+		"11:00 1 d",
+	}, "\n")
+
+	real_output := bytes.NewBufferString("")
+	app := NewApp(strings.NewReader(input), real_output)
+	app.SetQueueConfig(QueueConfig{QUEUE_UNLIMITED, OVERFLOW_REJECT})
+	app.SetSessionLimits(SessionLimits{Default: 60, Tables: map[uint]int{2: 10}})
+	app.SetWaitLimit(20)
+
+	if err := app.Process(); err != nil {
+		t.Fatalf("app process error: %v", err)
+	}
+
+	// b takes released table and its short session ends before c gives up
+	expected := strings.Join([]string{
+		"09:12 3 c",
+		"09:15 15 r 2",
+		"09:15 12 b 2",
+		"09:25 16 b 2",
+		"09:25 12 c 2",
+		"09:35 16 c 2",
+		"10:05 16 a 1",
+		"11:00 1 d",
+	}, "\n") + "\n"
+	if !strings.Contains(real_output.String(), expected) {
+		t.Errorf("Expected events:\n%s\nReal output:\n%s", expected, real_output.String())
+	}
+}
+
+func TestAppEventsAfterClose(t *testing.T) {
+	input := strings.Join([]string{
+		"1",
+		"09:00 19:00",
+		"10",
+		"09:10 1 a",
+		"09:10 2 a 1",
+		"09:20 1 b",
+		"09:20 3 b",
+		"19:10 1 c",
+		"19:20 1 d",
+	}, "\n")
+
+	real_output := bytes.NewBufferString("")
+	app := NewApp(strings.NewReader(input), real_output)
+	app.SetRenderer(TextRenderer{Queue: true})
+
+	if err := app.Process(); err != nil {
+		t.Fatalf("app process error: %v", err)
+	}
+
+	// Club is closed only once
+	expected := strings.Join([]string{
+		"09:20 3 b",
+		"19:00 11 a",
+		"19:00 11 b",
+		"19:10 1 c",
+		"19:10 13 NotOpenYet",
+		"19:20 1 d",
+		"19:20 13 NotOpenYet",
+		"19:00",
+	}, "\n") + "\n"
+	if !strings.Contains(real_output.String(), expected) {
+		t.Errorf("Expected events:\n%s\nReal output:\n%s", expected, real_output.String())
+	}
+
+	if waiting := app.state.QueueStats().WaitingAtClose; waiting != 1 {
+		t.Errorf("Expected 1 client waiting at close, got %d", waiting)
+	}
+}
+
+func TestStateScheduleInPast(t *testing.T) {
+	s := MakeState()
+	s.InitTables(1)
+	s.SetWorkDay(Time{9, 0}, Time{19, 0})
+	s.current_time = Time{10, 30}
+
+	s.schedule(timer{at: Time{10, 0}, kind: TIMER_WAIT_TIMEOUT, client: "a", since: Time{9, 30}})
+
+	if len(s.timers) != 2 || s.timers[0].at != (Time{10, 30}) || s.timers[1].kind != TIMER_CLUB_CLOSE {
+		t.Errorf("Timer in the past must fire at the current time: %v", s.timers)
+	}
+}
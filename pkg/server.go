@@ -0,0 +1,236 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrMethodNotAllowed = errors.New("method not allowed")
+)
+
+// HTTP API over live state of the club
+type Server struct {
+	mu       sync.Mutex
+	state    State
+	registry *EventRegistry
+	mux      *http.ServeMux
+}
+
+// Creates server for the club described by header of input file
+func NewServer(header io.Reader, pricing PricingFactory) (*Server, error) {
+	app := NewApp(header, io.Discard)
+	app.SetPricing(pricing)
+	if err := app.readClubInfo(); err != nil {
+		return nil, err
+	}
+
+	srv := &Server{state: app.state, registry: app.registry, mux: http.NewServeMux()}
+	srv.mux.HandleFunc("/events", srv.handleEvents)
+	srv.mux.HandleFunc("/tables", srv.handleTables)
+	srv.mux.HandleFunc("/queue", srv.handleQueue)
+	srv.mux.HandleFunc("/revenue", srv.handleRevenue)
+
+	return srv, nil
+}
+
+// Change which events are accepted. Default registry is used if not set
+func (srv *Server) SetEventRegistry(registry *EventRegistry) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.registry = registry
+}
+
+func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	srv.mux.ServeHTTP(w, r)
+}
+
+// Same fields as in the line of input file. Table is a shortcut for the
+// first argument, other arguments are passed as they are written in the file
+type eventRequest struct {
+	Time   string   `json:"time"`
+	Id     int      `json:"id"`
+	Client string   `json:"client"`
+	Table  uint     `json:"table,omitempty"`
+	Args   []string `json:"args,omitempty"`
+}
+
+// Line of input file for the request
+func (r eventRequest) String() string {
+	line := fmt.Sprintf("%s %d %s", r.Time, r.Id, r.Client)
+	if r.Table != 0 {
+		line += fmt.Sprintf(" %d", r.Table)
+	}
+	for _, arg := range r.Args {
+		line += " " + arg
+	}
+	return line
+}
+
+// Checks every field on its own, so one field cannot add pieces to the line
+func (r eventRequest) validate() error {
+	if _, err := MakeTime(r.Time); err != nil {
+		return &ParseError{Column: 1, Field: FIELD_TIME, Err: err}
+	}
+
+	if !ValidClientName(r.Client) {
+		return &ParseError{Column: 1, Field: FIELD_CLIENT, Err: ErrInvalidEventFormat}
+	}
+
+	for _, arg := range r.Args {
+		if len(strings.Fields(arg)) != 1 || strings.TrimSpace(arg) != arg {
+			return &ParseError{Column: 1, Field: FIELD_ARGUMENTS, Err: ErrInvalidEventFormat}
+		}
+	}
+
+	return nil
+}
+
+type eventResponse struct {
+	Event     jsonEvent   `json:"event"`
+	Generated []jsonEvent `json:"generated"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+	Field string `json:"field,omitempty"`
+}
+
+type tableResponse struct {
+	Table  uint     `json:"table"`
+	Client string   `json:"client,omitempty"`
+	Since  *Time    `json:"since,omitempty"`
+	Profit uint     `json:"profit"`
+	Usage  Duration `json:"usage"`
+}
+
+type queueResponse struct {
+	Clients []string `json:"clients"`
+}
+
+type revenueResponse struct {
+	Total  uint        `json:"total"`
+	Tables []jsonTable `json:"tables"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	response := errorResponse{Error: err.Error()}
+
+	var parse_err *ParseError
+	if errors.As(err, &parse_err) {
+		response.Error = parse_err.Err.Error()
+		response.Field = parse_err.Field
+	}
+
+	writeJSON(w, status, response)
+}
+
+// POST /events applies event and returns generated events
+func (srv *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed)
+		return
+	}
+
+	var request eventRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := request.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	event, err := srv.registry.Parse(request.String(), srv.state)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	generated, err := srv.state.Apply(event)
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+
+	response := eventResponse{makeJSONEvent(event), make([]jsonEvent, 0, len(generated))}
+	for _, e := range generated {
+		response.Generated = append(response.Generated, makeJSONEvent(e))
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// GET /tables returns occupation of the tables
+func (srv *Server) handleTables(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed)
+		return
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	s := &srv.state
+	response := make([]tableResponse, 0, s.table_count)
+	for i := uint(0); i < s.table_count; i++ {
+		table := tableResponse{Table: i + 1, Profit: s.tables_profit[i], Usage: s.tables_usage[i]}
+		if s.TableBusy(i + 1) {
+			since := s.tables_start_time[i]
+			table.Client = s.tables_occupation[i]
+			table.Since = &since
+		}
+		response = append(response, table)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// GET /queue returns waiting clients in order
+func (srv *Server) handleQueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed)
+		return
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, queueResponse{srv.state.queue.Items()})
+}
+
+// GET /revenue returns money earned by the tables so far
+func (srv *Server) handleRevenue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed)
+		return
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	s := &srv.state
+	response := revenueResponse{Tables: make([]jsonTable, 0, s.table_count)}
+	for i := uint(0); i < s.table_count; i++ {
+		response.Total += s.tables_profit[i]
+		response.Tables = append(response.Tables, jsonTable{i + 1, s.tables_profit[i], s.tables_usage[i]})
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
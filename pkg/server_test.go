@@ -0,0 +1,208 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	srv, err := NewServer(strings.NewReader("2\n09:00 19:00\n10\n"), NewHourlyPricing)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func postEvent(t *testing.T, ts *httptest.Server, body string) (int, eventResponse) {
+	resp, err := http.Post(ts.URL+"/events", "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var out eventResponse
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			t.Fatalf("Invalid response: %v", err)
+		}
+	}
+
+	return resp.StatusCode, out
+}
+
+func getJSON(t *testing.T, ts *httptest.Server, path string, v any) {
+	resp, err := http.Get(ts.URL + path)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected status for %s: %d", path, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+}
+
+func TestServerEvents(t *testing.T) {
+	ts := newTestServer(t)
+
+	events := []string{
+		`{"time": "09:00", "id": 1, "client": "a"}`,
+		`{"time": "09:01", "id": 2, "client": "a", "table": 1}`,
+		`{"time": "09:02", "id": 1, "client": "b"}`,
+		`{"time": "09:03", "id": 2, "client": "b", "table": 2}`,
+		`{"time": "09:04", "id": 1, "client": "c"}`,
+		`{"time": "09:05", "id": 3, "client": "c"}`,
+	}
+
+	for _, e := range events {
+		if status, _ := postEvent(t, ts, e); status != http.StatusOK {
+			t.Fatalf("Unexpected status %d for %s", status, e)
+		}
+	}
+
+	var queue queueResponse
+	getJSON(t, ts, "/queue", &queue)
+	if len(queue.Clients) != 1 || queue.Clients[0] != "c" {
+		t.Errorf("Invalid queue: %v", queue.Clients)
+	}
+
+	status, response := postEvent(t, ts, `{"time": "10:30", "id": 4, "client": "a"}`)
+	if status != http.StatusOK {
+		t.Fatalf("Unexpected status %d", status)
+	}
+
+	if len(response.Generated) != 1 || response.Generated[0].Id != EVENT_ID_OUT_CLIENT_TAKE_A_SEAT || response.Generated[0].Client != "c" || response.Generated[0].Table != 1 {
+		t.Errorf("Expected client c to take table 1: %+v", response.Generated)
+	}
+
+	var tables []tableResponse
+	getJSON(t, ts, "/tables", &tables)
+	if len(tables) != 2 || tables[0].Client != "c" || tables[0].Since == nil || *tables[0].Since != (Time{10, 30}) {
+		t.Errorf("Invalid tables: %+v", tables)
+	}
+
+	var revenue revenueResponse
+	getJSON(t, ts, "/revenue", &revenue)
+	if revenue.Total != 20 || revenue.Tables[0].Usage != MakeDuration(1, 29) {
+		t.Errorf("Invalid revenue: %+v", revenue)
+	}
+
+	status, response = postEvent(t, ts, `{"time": "10:40", "id": 2, "client": "x", "table": 2}`)
+	if status != http.StatusOK || len(response.Generated) != 1 || response.Generated[0].Message != MSG_PLACE_IS_BUSY {
+		t.Errorf("Expected PlaceIsBusy error event: %d %+v", status, response)
+	}
+}
+
+func TestServerEventArgs(t *testing.T) {
+	srv, err := NewServer(strings.NewReader("2\n09:00 19:00\n10\n"), NewHourlyPricing)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	srv.SetEventRegistry(newTestRegistry(t))
+
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	events := []string{
+		`{"time": "09:00", "id": 5, "client": "r", "table": 1, "args": ["10:00", "12:00"]}`,
+		`{"time": "10:05", "id": 1, "client": "a"}`,
+	}
+
+	for _, e := range events {
+		if status, _ := postEvent(t, ts, e); status != http.StatusOK {
+			t.Fatalf("Unexpected status %d for %s", status, e)
+		}
+	}
+
+	status, response := postEvent(t, ts, `{"time": "10:05", "id": 2, "client": "a", "table": 1}`)
+	if status != http.StatusOK || len(response.Generated) != 1 || response.Generated[0].Message != MSG_TABLE_RESERVED {
+		t.Errorf("Expected TableReserved error event: %d %+v", status, response)
+	}
+
+	// Custom event of the registry
+	status, response = postEvent(t, ts, `{"time": "10:10", "id": 105, "client": "a", "args": ["2", "11:30"]}`)
+	if status != http.StatusOK || len(response.Generated) != 1 || response.Generated[0].Id != testEventIdPingOut {
+		t.Errorf("Expected ping output event: %d %+v", status, response)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	ts := newTestServer(t)
+
+	if status, _ := postEvent(t, ts, `{"time": "10:00", "id": 1, "client": "a"}`); status != http.StatusOK {
+		t.Fatalf("Unexpected status %d", status)
+	}
+
+	test_cases := []struct {
+		body   string
+		status int
+	}{
+		{`{"time": "9:00", "id": 1, "client": "b"}`, http.StatusBadRequest},
+		{`{"time": "10:00", "id": 1, "client": "B"}`, http.StatusBadRequest},
+		{`{"time": "10:00", "id": 2, "client": "a", "table": 3}`, http.StatusBadRequest},
+		{`{"time": "10:00", "id": 9, "client": "a"}`, http.StatusBadRequest},
+		{`not json`, http.StatusBadRequest},
+		{`{"time": "10:00", "id": 2, "client": "a 2"}`, http.StatusBadRequest},
+		{`{"time": "10:00 2", "id": 1, "client": "b"}`, http.StatusBadRequest},
+		{`{"time": "10:00", "id": 2, "client": "a", "args": ["2 10:00"]}`, http.StatusBadRequest},
+		{`{"time": "09:30", "id": 1, "client": "b"}`, http.StatusConflict},
+	}
+
+	for _, tc := range test_cases {
+		if status, _ := postEvent(t, ts, tc.body); status != tc.status {
+			t.Errorf("Expected status %d for %s, got %d", tc.status, tc.body, status)
+		}
+	}
+
+	resp, err := http.Get(ts.URL + "/events")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected method not allowed, got %d", resp.StatusCode)
+	}
+}
+
+func TestServerConcurrent(t *testing.T) {
+	ts := newTestServer(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"time": "10:00", "id": 1, "client": "c%d"}`, i)
+			resp, err := http.Post(ts.URL+"/events", "application/json", bytes.NewBufferString(body))
+			if err != nil {
+				t.Errorf("Request failed: %v", err)
+				return
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("Unexpected status %d", resp.StatusCode)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var revenue revenueResponse
+	getJSON(t, ts, "/revenue", &revenue)
+	if revenue.Total != 0 {
+		t.Errorf("Unexpected revenue: %d", revenue.Total)
+	}
+}
@@ -0,0 +1,44 @@
+package pkg
+
+// Client sits at the table until leaving or close of the club
+const SESSION_UNLIMITED = 0
+
+// Maximum minutes of one session at the table
+type SessionLimits struct {
+	Default int          // For tables without their own limit
+	Tables  map[uint]int // By table number
+}
+
+// Session limit of the table
+func (l SessionLimits) For(table uint) int {
+	if limit, ok := l.Tables[table]; ok {
+		return limit
+	}
+	return l.Default
+}
+
+// Registers end of the session which began now
+func (s *State) scheduleSessionEnd(table uint, client string) {
+	limit := s.session_limits.For(table)
+	if limit == SESSION_UNLIMITED {
+		return
+	}
+
+	s.scheduleAfter(timer{kind: TIMER_SESSION_END, client: client, table: table, since: s.current_time}, limit)
+}
+
+// Frees the table if the client sits at it since the timer was registered.
+// Client may still be at the table at the last minute of the limit.
+// Waiting client takes the freed table
+func (s *State) endSession(t timer) {
+	table_id := t.table - 1
+	if s.tables_occupation[table_id] != t.client || s.tables_start_time[table_id] != t.since {
+		return
+	}
+
+	s.current_time = t.at
+	s.LeaveTable(t.client)
+	s.Emit(NewSessionExpiredOutputEvent(s.current_time, t.client, t.table))
+
+	s.SeatFromQueue(t.table)
+}
@@ -0,0 +1,429 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+)
+
+// Version of snapshot format. Increased when state is added or changed, so
+// older programs reject snapshot instead of restoring it without that state.
+// Fields which older programs may safely skip keep the version
+const SNAPSHOT_VERSION = 7
+
+var (
+	ErrSnapshotVersion = errors.New("unsupported snapshot version")
+	ErrInvalidSnapshot = errors.New("invalid snapshot")
+)
+
+// Everything needed to continue processing from the middle of input.
+// Pricing policy and event registry are code, so they are not saved
+// and must be set up again before restore
+type snapshot struct {
+	Version int `json:"version"`
+	Line    int `json:"line"`
+
+	// Beginning of the day and events are already written in streaming mode
+	DayWritten bool `json:"day_written,omitempty"`
+
+	State       stateSnapshot        `json:"state"`
+	Totals      []jsonTable          `json:"totals,omitempty"` // Sum over closed days
+	Diagnostics []diagnosticSnapshot `json:"diagnostics,omitempty"`
+}
+
+type stateSnapshot struct {
+	Date   Date `json:"date,omitempty"`
+	Tables uint `json:"tables"`
+	Open   Time `json:"open"`
+	Close  Time `json:"close"`
+	Price  uint `json:"price"`
+
+	CurrentTime Time `json:"current_time"`
+	LastTime    Time `json:"last_time"`
+
+	Clients     []string        `json:"clients"`
+	ClientsPaid map[string]uint `json:"clients_paid"`
+	Visits      []visitSnapshot `json:"visits"`
+
+	TableStates []tableSnapshot `json:"table_states"`
+
+	Reservations     []reservationSnapshot `json:"reservations"`
+	ReservationGrace int                   `json:"reservation_grace"`
+	WaitLimit        int                   `json:"wait_limit"`
+
+	SessionLimit       int          `json:"session_limit"`
+	TableSessionLimits map[uint]int `json:"table_session_limits,omitempty"`
+
+	Queue         []string              `json:"queue"` // In order clients will be seated
+	QueueCapacity int                   `json:"queue_capacity"`
+	QueueOverflow OverflowPolicy        `json:"queue_overflow"`
+	QueueStats    queueSnapshot         `json:"queue_stats"`
+	QueueTimeline []queueSampleSnapshot `json:"queue_timeline"`
+	VIP           []string              `json:"vip"`
+
+	Timers []timerSnapshot `json:"timers"` // In order they fire
+	Closed bool            `json:"closed"`
+
+	Events []eventSnapshot `json:"events"`
+}
+
+type tableSnapshot struct {
+	Client   string      `json:"client,omitempty"`
+	Start    Time        `json:"start"`
+	Profit   uint        `json:"profit"`
+	Usage    Duration    `json:"usage"`
+	Sessions uint        `json:"sessions"`
+	Longest  Duration    `json:"longest"`
+	Hourly   hourlyUsage `json:"hourly"` // Busy minutes in every hour of the day
+}
+
+type queueSnapshot struct {
+	Waited         uint     `json:"waited"`
+	TotalWait      Duration `json:"total_wait"`
+	MaxWait        Duration `json:"max_wait"`
+	Walkaways      uint     `json:"walkaways"`
+	WaitingAtClose uint     `json:"waiting_at_close"`
+	MaxLength      uint     `json:"max_length"`
+}
+
+type queueSampleSnapshot struct {
+	Time   Time `json:"time"`
+	Length int  `json:"length"`
+}
+
+type visitSnapshot struct {
+	Client       string        `json:"client"`
+	Arrived      Time          `json:"arrived"`
+	Left         Time          `json:"left"`
+	Present      bool          `json:"present"`
+	Waiting      Duration      `json:"waiting"`
+	Sessions     []jsonSession `json:"sessions"`
+	IsWaiting    bool          `json:"is_waiting"`
+	WaitingSince Time          `json:"waiting_since"`
+}
+
+type timerSnapshot struct {
+	At     Time      `json:"at"`
+	Kind   timerKind `json:"kind"`
+	Client string    `json:"client,omitempty"`
+	Table  uint      `json:"table,omitempty"`
+	Since  Time      `json:"since"`
+}
+
+type reservationSnapshot struct {
+	Client string `json:"client"`
+	Table  uint   `json:"table"`
+	Start  Time   `json:"start"`
+	End    Time   `json:"end"`
+	Seated bool   `json:"seated"`
+}
+
+// Event is kept as it is written, so restored events are printed the same way
+type eventSnapshot struct {
+	jsonEvent
+	Line string `json:"line"`
+}
+
+type diagnosticSnapshot struct {
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Field  string `json:"field"`
+	Text   string `json:"text"`
+	Code   string `json:"code,omitempty"` // Sentinel error, empty if reason is not one of them
+	Reason string `json:"reason"`
+}
+
+// Codes of sentinel errors of diagnostics. Codes must not be changed,
+// since they are stored in snapshots
+var diagnostic_codes = map[string]error{
+	"eof":                  ErrEOF,
+	"invalid_order":        ErrInvalidOrderOfEvent,
+	"invalid_event_format": ErrInvalidEventFormat,
+	"unknown_event_type":   ErrUnknownEventType,
+	"invalid_time_format":  ErrInvalidTimeFormat,
+	"time_out_of_range":    ErrTimeOutOfRange,
+	"invalid_date_format":  ErrInvalidDateFormat,
+	"no_tables":            ErrNoTables,
+	"zero_price":           ErrZeroPrice,
+}
+
+func makeDiagnosticSnapshot(e *ParseError) diagnosticSnapshot {
+	out := diagnosticSnapshot{e.Line, e.Column, e.Field, e.Text, "", e.Err.Error()}
+	for code, err := range diagnostic_codes {
+		if errors.Is(e.Err, err) {
+			out.Code = code
+		}
+	}
+	return out
+}
+
+// Diagnostic with the sentinel error, so errors.Is works as before the snapshot
+func (d diagnosticSnapshot) restore() *ParseError {
+	err, ok := diagnostic_codes[d.Code]
+	if !ok {
+		err = errors.New(d.Reason)
+	}
+	return &ParseError{d.Line, d.Column, d.Field, d.Text, err}
+}
+
+// Event restored from snapshot. It was already applied, so it only can be written
+type restoredEvent struct {
+	event eventSnapshot
+}
+
+func (e restoredEvent) String() string {
+	return e.event.Line
+}
+
+func (e restoredEvent) Time() Time {
+	return e.event.Time
+}
+
+func (e restoredEvent) Id() int {
+	return e.event.Id
+}
+
+func (e restoredEvent) Client() string {
+	return e.event.Client
+}
+
+func (e restoredEvent) Table() uint {
+	return e.event.Table
+}
+
+func (e restoredEvent) Skipped() uint {
+	return e.event.Skipped
+}
+
+func (e restoredEvent) Message() string {
+	return e.event.Message
+}
+
+func (s State) snapshot() stateSnapshot {
+	out := stateSnapshot{
+		Date:             s.date,
+		Tables:           s.table_count,
+		Open:             s.time_start,
+		Close:            s.time_end,
+		Price:            s.price,
+		CurrentTime:      s.current_time,
+		LastTime:         s.last_time,
+		Clients:          s.Clients(),
+		ClientsPaid:      s.clients_paid,
+		Visits:           make([]visitSnapshot, 0, len(s.visits)),
+		TableStates:      make([]tableSnapshot, 0, s.table_count),
+		Reservations:     make([]reservationSnapshot, 0, len(s.reservations)),
+		ReservationGrace: s.reservation_grace,
+		WaitLimit:        s.wait_limit,
+
+		SessionLimit:       s.session_limits.Default,
+		TableSessionLimits: s.session_limits.Tables,
+		Queue:              s.queue.Items(),
+		QueueCapacity:      s.queue_config.Capacity,
+		QueueOverflow:      s.queue_config.Overflow,
+		QueueStats:         queueSnapshot(s.queue_stats),
+		QueueTimeline:      make([]queueSampleSnapshot, 0, len(s.queue_timeline)),
+		VIP:                make([]string, 0, len(s.vip)),
+		Timers:             make([]timerSnapshot, 0, len(s.timers)),
+		Closed:             s.closed,
+		Events:             make([]eventSnapshot, 0, len(s.events)),
+	}
+
+	sort.Strings(out.Clients)
+
+	for _, v := range s.visits {
+		visit := visitSnapshot{v.Client, v.Arrived, v.Left, v.Present, v.Waiting, make([]jsonSession, 0, len(v.Sessions)), v.waiting, v.waiting_since}
+		for _, session := range v.Sessions {
+			visit.Sessions = append(visit.Sessions, jsonSession(session))
+		}
+		out.Visits = append(out.Visits, visit)
+	}
+
+	for i := uint(0); i < s.table_count; i++ {
+		out.TableStates = append(out.TableStates, tableSnapshot{s.tables_occupation[i], s.tables_start_time[i], s.tables_profit[i], s.tables_usage[i], s.tables_sessions[i], s.tables_longest[i], s.tables_hourly[i]})
+	}
+
+	for _, r := range s.reservations {
+		out.Reservations = append(out.Reservations, reservationSnapshot{r.Client, r.Table, r.Start, r.End, r.seated})
+	}
+
+	for _, sample := range s.queue_timeline {
+		out.QueueTimeline = append(out.QueueTimeline, queueSampleSnapshot{sample.time, sample.length})
+	}
+
+	for client := range s.vip {
+		out.VIP = append(out.VIP, client)
+	}
+	sort.Strings(out.VIP)
+
+	for _, t := range s.timers {
+		out.Timers = append(out.Timers, timerSnapshot{t.at, t.kind, t.client, t.table, t.since})
+	}
+
+	for _, e := range s.events {
+		out.Events = append(out.Events, eventSnapshot{makeJSONEvent(e), e.String()})
+	}
+
+	return out
+}
+
+// Creates state from snapshot. Pricing is created by the factory
+func restoreState(in stateSnapshot, pricing PricingFactory) (State, error) {
+	s := MakeState()
+
+	if len(in.TableStates) != int(in.Tables) {
+		return s, ErrInvalidSnapshot
+	}
+
+	s.date = in.Date
+	s.queue_config = QueueConfig{in.QueueCapacity, in.QueueOverflow}
+	s.queue_stats = QueueStats(in.QueueStats)
+	s.reservation_grace = in.ReservationGrace
+	s.wait_limit = in.WaitLimit
+	s.session_limits = SessionLimits{in.SessionLimit, in.TableSessionLimits}
+	for _, client := range in.VIP {
+		s.vip[client] = struct{}{}
+	}
+
+	s.InitTables(in.Tables)
+	s.time_start = in.Open
+	s.time_end = in.Close
+	s.current_time = in.CurrentTime
+	s.last_time = in.LastTime
+	s.price = in.Price
+	s.pricing = pricing(in.Price)
+
+	for _, client := range in.Clients {
+		s.client_set[client] = struct{}{}
+	}
+
+	for client, paid := range in.ClientsPaid {
+		s.clients_paid[client] = paid
+	}
+
+	for _, v := range in.Visits {
+		visit := Visit{Client: v.Client, Arrived: v.Arrived, Left: v.Left, Present: v.Present, Waiting: v.Waiting}
+		for _, session := range v.Sessions {
+			visit.Sessions = append(visit.Sessions, BilledSession(session))
+		}
+		visit.waiting = v.IsWaiting
+		visit.waiting_since = v.WaitingSince
+		s.visits = append(s.visits, &visit)
+		if visit.Present {
+			s.clients_visit[visit.Client] = &visit
+		}
+	}
+
+	for i, table := range in.TableStates {
+		s.tables_start_time[i] = table.Start
+		s.tables_profit[i] = table.Profit
+		s.tables_usage[i] = table.Usage
+		s.tables_sessions[i] = table.Sessions
+		s.tables_longest[i] = table.Longest
+		s.tables_hourly[i] = table.Hourly
+
+		if len(table.Client) != 0 {
+			s.tables_occupation[i] = table.Client
+			s.clients_current_table[table.Client] = uint(i)
+		}
+	}
+
+	for _, r := range in.Reservations {
+		if r.Table == 0 || in.Tables < r.Table {
+			return s, ErrInvalidSnapshot
+		}
+
+		s.reservations = append(s.reservations, &Reservation{r.Client, r.Table, r.Start, r.End, r.Seated})
+	}
+
+	for _, client := range in.Queue {
+		s.queue.ForcePush(client, s.queuePriority(client))
+	}
+
+	for _, sample := range in.QueueTimeline {
+		s.queue_timeline = append(s.queue_timeline, queueSample{sample.Time, sample.Length})
+	}
+
+	for _, t := range in.Timers {
+		if in.Tables < t.Table || (t.Table == 0 && (t.Kind == TIMER_SESSION_END || t.Kind == TIMER_RESERVATION_RELEASE)) {
+			return s, ErrInvalidSnapshot
+		}
+
+		s.timers = append(s.timers, timer{t.At, t.Kind, t.Client, t.Table, t.Since})
+	}
+	s.closed = in.Closed
+
+	for _, e := range in.Events {
+		s.events = append(s.events, restoredEvent{e})
+	}
+
+	return s, nil
+}
+
+// Makes Process write snapshot to w at the end of input instead of closing
+// the working day. Processing can be continued later using Restore
+func (app *App) SetCheckpoint(w io.Writer) {
+	app.checkpoint = w
+}
+
+// Writes snapshot of the processing
+func (app *App) WriteSnapshot(w io.Writer) error {
+	out := snapshot{
+		Version:    SNAPSHOT_VERSION,
+		Line:       app.line,
+		DayWritten: app.day_written,
+		State:      app.state.snapshot(),
+	}
+
+	for i := range app.totals.Profit {
+		out.Totals = append(out.Totals, jsonTable{uint(i + 1), app.totals.Profit[i], app.totals.Usage[i]})
+	}
+
+	for _, e := range app.diagnostics {
+		out.Diagnostics = append(out.Diagnostics, makeDiagnosticSnapshot(e))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+// Continues processing from snapshot. Input of the app must contain only
+// lines after ones processed before the snapshot, header is not read again.
+// Settings stored in the snapshot replace settings of the app
+func (app *App) Restore(r io.Reader) error {
+	var in snapshot
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return err
+	}
+
+	if in.Version != SNAPSHOT_VERSION {
+		return ErrSnapshotVersion
+	}
+
+	state, err := restoreState(in.State, app.pricing)
+	if err != nil {
+		return err
+	}
+
+	app.state = state
+	app.line = in.Line
+	app.totals = Totals{}
+	for _, table := range in.Totals {
+		app.totals.Profit = append(app.totals.Profit, table.Profit)
+		app.totals.Usage = append(app.totals.Usage, table.Usage)
+	}
+	app.header = true
+
+	// Events written before the snapshot are not kept, so only rest of them can be written
+	app.day_written = in.DayWritten
+	app.streaming = app.streaming || in.DayWritten
+
+	app.diagnostics = nil
+	for _, e := range in.Diagnostics {
+		app.diagnostics = append(app.diagnostics, e.restore())
+	}
+
+	return nil
+}
@@ -0,0 +1,201 @@
+package pkg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Runs the app on the lines splitting them into two runs at every possible line
+// and compares output with uninterrupted run
+func testSnapshotSplits(t *testing.T, lines []string, setup func(app *App)) {
+	full_output := bytes.NewBufferString("")
+	app := NewApp(strings.NewReader(strings.Join(lines, "\n")), full_output)
+	setup(&app)
+	// Lenient mode returns collected errors
+	full_err := app.Process()
+
+	// Header is never split
+	for split := 3; split <= len(lines); split++ {
+		real_output := bytes.NewBufferString("")
+		checkpoint := bytes.NewBufferString("")
+
+		first := NewApp(strings.NewReader(strings.Join(lines[:split], "\n")), real_output)
+		setup(&first)
+		first.SetCheckpoint(checkpoint)
+		if err := first.Process(); err != nil {
+			t.Fatalf("split %d: first run error: %v", split, err)
+		}
+
+		second := NewApp(strings.NewReader(strings.Join(lines[split:], "\n")), real_output)
+		setup(&second)
+		if err := second.Restore(checkpoint); err != nil {
+			t.Fatalf("split %d: restore error: %v", split, err)
+		}
+		if err := second.Process(); fmt.Sprint(err) != fmt.Sprint(full_err) {
+			t.Fatalf("split %d: expected error: %v, got: %v", split, full_err, err)
+		}
+
+		if real_output.String() != full_output.String() {
+			t.Fatalf("split %d: expected:\n%s\nreal output:\n%s", split, full_output.String(), real_output.String())
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	test_files := []string{
+		"stock.txt",
+		"multi_day.txt",
+		"overnight.txt",
+		"reservations.txt",
+		"client_switch_table.txt",
+	}
+
+	for _, name := range test_files {
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile("../test_cases/input/" + name)
+			if err != nil {
+				t.Fatalf("Error: %v", err)
+			}
+
+			lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+			testSnapshotSplits(t, lines, func(app *App) {
+				app.SetRenderer(JSONRenderer{Clients: true})
+			})
+		})
+	}
+}
+
+func TestSnapshotQueue(t *testing.T) {
+	lines := []string{
+		"1",
+		"09:00 19:00",
+		"10",
+		"09:10 1 a",
+		"09:10 2 a 1",
+		"09:20 1 b",
+		"09:20 3 b",
+		"09:30 1 v",
+		"09:30 3 v",
+		"09:40 1 c",
+		"09:40 3 c",
+		"10:00 4 a",
+		"10:30 4 v",
+	}
+
+	testSnapshotSplits(t, lines, func(app *App) {
+		app.SetQueueConfig(QueueConfig{QUEUE_UNLIMITED, OVERFLOW_REJECT})
+		app.SetVIP([]string{"v"})
+		app.SetRenderer(TextRenderer{Clients: true})
+	})
+}
+
+func TestSnapshotLenient(t *testing.T) {
+	lines := []string{
+		"2",
+		"09:00 19:00",
+		"10",
+		"09:10 1 a",
+		"09:10 2 a 7",
+		"09:20 1 B",
+		"09:30 2 a 1",
+		"10:00 4 a",
+	}
+
+	testSnapshotSplits(t, lines, func(app *App) {
+		app.SetLenient(true)
+	})
+}
+
+func TestSnapshotDiagnostics(t *testing.T) {
+	input := strings.Join([]string{
+		"2",
+		"09:00 19:00",
+		"10",
+		"09:20 1 B",
+		"09:30 1 a",
+		"09:10 1 b",
+	}, "\n")
+
+	checkpoint := bytes.NewBufferString("")
+	first := NewApp(strings.NewReader(input), bytes.NewBufferString(""))
+	first.SetLenient(true)
+	first.SetCheckpoint(checkpoint)
+	if err := first.Process(); err != nil {
+		t.Fatalf("first run error: %v", err)
+	}
+
+	second := NewApp(strings.NewReader(""), bytes.NewBufferString(""))
+	if err := second.Restore(checkpoint); err != nil {
+		t.Fatalf("restore error: %v", err)
+	}
+
+	diagnostics := second.Diagnostics()
+	if len(diagnostics) != 2 || !errors.Is(diagnostics[0], ErrInvalidEventFormat) || !errors.Is(diagnostics[1], ErrInvalidOrderOfEvent) {
+		t.Errorf("Restored diagnostics must wrap sentinel errors: %v", diagnostics)
+	}
+}
+
+func TestSnapshotVersion(t *testing.T) {
+	app := NewApp(strings.NewReader(""), bytes.NewBufferString(""))
+	err := app.Restore(strings.NewReader(`{"version": 100}`))
+	if !errors.Is(err, ErrSnapshotVersion) {
+		t.Errorf("Expected version error, got: %v", err)
+	}
+}
+
+func TestSnapshotStreaming(t *testing.T) {
+	data, err := os.ReadFile("../test_cases/input/multi_day.txt")
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	testSnapshotSplits(t, lines, func(app *App) {
+		app.SetStreaming(true)
+	})
+}
+
+func TestSnapshotWaitLimit(t *testing.T) {
+	lines := []string{
+		"1",
+		"09:00 19:00",
+		"10",
+		"09:10 1 a",
+		"09:10 2 a 1",
+		"09:20 1 b",
+		"09:20 3 b",
+		"09:30 1 c",
+		"09:30 3 c",
+		"10:00 4 a",
+		"18:20 1 d",
+		"18:20 3 d",
+	}
+
+	testSnapshotSplits(t, lines, func(app *App) {
+		app.SetQueueConfig(QueueConfig{QUEUE_UNLIMITED, OVERFLOW_REJECT})
+		app.SetWaitLimit(30)
+	})
+}
+
+func TestSnapshotSessionLimits(t *testing.T) {
+	lines := []string{
+		"2",
+		"09:00 19:00",
+		"10",
+		"09:10 1 a",
+		"09:10 2 a 1",
+		"09:20 1 b",
+		"09:20 2 b 2",
+		"09:30 1 c",
+		"09:30 3 c",
+		"11:00 4 c",
+	}
+
+	testSnapshotSplits(t, lines, func(app *App) {
+		app.SetSessionLimits(SessionLimits{Default: 60, Tables: map[uint]int{2: 30}})
+	})
+}
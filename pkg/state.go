@@ -5,23 +5,69 @@ import (
 	"sort"
 )
 
+// What happens when client wants to wait but the queue is full
+type OverflowPolicy int
+
+const (
+	OVERFLOW_REJECT       OverflowPolicy = iota // Newcomer leaves
+	OVERFLOW_EVICT_OLDEST                       // The longest waiting client leaves, newcomer waits
+	OVERFLOW_ACCEPT                             // Newcomer waits over capacity with error event
+)
+
+// Capacity of the queue equal to the number of tables
+const QUEUE_CAPACITY_TABLES = -2
+
+// Waiting queue settings
+type QueueConfig struct {
+	Capacity int // Number of clients, QUEUE_CAPACITY_TABLES or QUEUE_UNLIMITED
+	Overflow OverflowPolicy
+}
+
+// Priorities of clients in the waiting queue
+const (
+	PRIORITY_REGULAR = 0
+	PRIORITY_VIP     = 1
+)
+
 type State struct {
+	date        Date // Empty if log is not split by days
 	table_count uint
 	time_start  Time
 	time_end    Time
 	price       uint
+	pricing     PricingPolicy
 
 	current_time Time
+	last_time    Time // Time of the last applied input event
 
 	client_set            map[string]struct{}
 	clients_current_table map[string]uint
+	clients_paid          map[string]uint // Paid by clients in the working day
+	clients_visit         map[string]*Visit
+
+	visits []*Visit
 
 	tables_occupation []string
 	tables_profit     []uint
 	tables_start_time []Time
-	tables_usage      []Time
+	tables_usage      []Duration
+	tables_sessions   []uint
+	tables_longest    []Duration
+	tables_hourly     []hourlyUsage
 
-	queue Queue[string]
+	reservations      []*Reservation
+	reservation_grace int // Minutes
+	wait_limit        int // Minutes client waits in the queue before leaving
+	session_limits    SessionLimits
+
+	queue          PriorityQueue[string]
+	queue_config   QueueConfig
+	queue_stats    QueueStats
+	queue_timeline []queueSample
+	vip            map[string]struct{}
+
+	timers []timer // In order they fire
+	closed bool    // Club is closed and everybody has left
 
 	events []Event
 }
@@ -30,7 +76,65 @@ func MakeState() State {
 	return State{
 		client_set:            make(map[string]struct{}),
 		clients_current_table: make(map[string]uint),
+		clients_paid:          make(map[string]uint),
+		clients_visit:         make(map[string]*Visit),
+		queue_config:          QueueConfig{QUEUE_CAPACITY_TABLES, OVERFLOW_REJECT},
+		vip:                   make(map[string]struct{}),
+		reservation_grace:     RESERVATION_GRACE_DEFAULT,
+	}
+}
+
+// Copy of the state which can be changed without affecting original
+func (s State) Clone() State {
+	out := s
+
+	out.client_set = cloneMap(s.client_set)
+	out.clients_current_table = cloneMap(s.clients_current_table)
+	out.clients_paid = cloneMap(s.clients_paid)
+	out.vip = cloneMap(s.vip)
+
+	// Visits are shared by the list and the map of current visits
+	visits := make(map[*Visit]*Visit, len(s.visits))
+	out.visits = make([]*Visit, 0, len(s.visits))
+	for _, v := range s.visits {
+		visit := *v
+		visit.Sessions = append([]BilledSession(nil), v.Sessions...)
+		visits[v] = &visit
+		out.visits = append(out.visits, &visit)
+	}
+	out.clients_visit = make(map[string]*Visit, len(s.clients_visit))
+	for client, v := range s.clients_visit {
+		out.clients_visit[client] = visits[v]
+	}
+
+	out.tables_occupation = append([]string(nil), s.tables_occupation...)
+	out.tables_profit = append([]uint(nil), s.tables_profit...)
+	out.tables_start_time = append([]Time(nil), s.tables_start_time...)
+	out.tables_usage = append([]Duration(nil), s.tables_usage...)
+	out.tables_sessions = append([]uint(nil), s.tables_sessions...)
+	out.tables_longest = append([]Duration(nil), s.tables_longest...)
+	out.tables_hourly = append([]hourlyUsage(nil), s.tables_hourly...)
+
+	out.reservations = make([]*Reservation, 0, len(s.reservations))
+	for _, r := range s.reservations {
+		reservation := *r
+		out.reservations = append(out.reservations, &reservation)
+	}
+
+	out.queue = s.queue.Clone()
+	out.queue_timeline = append([]queueSample(nil), s.queue_timeline...)
+	out.timers = append([]timer(nil), s.timers...)
+	out.events = append([]Event(nil), s.events...)
+
+	return out
+}
+
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	out := make(map[K]V, len(m))
+	for k, v := range m {
+		out[k] = v
 	}
+	return out
 }
 
 func (s *State) InitTables(size uint) {
@@ -38,9 +142,109 @@ func (s *State) InitTables(size uint) {
 	s.tables_occupation = make([]string, size)
 	s.tables_profit = make([]uint, size)
 	s.tables_start_time = make([]Time, size)
-	s.tables_usage = make([]Time, size)
+	s.tables_usage = make([]Duration, size)
+	s.tables_sessions = make([]uint, size)
+	s.tables_longest = make([]Duration, size)
+	s.tables_hourly = make([]hourlyUsage, size)
+
+	capacity := s.queue_config.Capacity
+	if capacity == QUEUE_CAPACITY_TABLES {
+		capacity = int(size)
+	}
+	s.queue = NewPriorityQueue[string](capacity)
+}
+
+// Clears everything what happened in previous working day
+func (s *State) NextDay(date Date) {
+	s.date = date
+	s.current_time = s.WorkDay().Begin()
+	s.last_time = s.current_time
+	s.client_set = make(map[string]struct{})
+	s.clients_current_table = make(map[string]uint)
+	s.clients_paid = make(map[string]uint)
+	s.clients_visit = make(map[string]*Visit)
+	s.visits = nil
+	s.reservations = nil
+	s.queue_stats = QueueStats{}
+	s.queue_timeline = nil
+	s.events = nil
+	s.InitTables(s.table_count)
+	s.scheduleClose()
+}
+
+// Pricing policy used to bill clients. Hourly pricing by default
+func (s State) Pricing() PricingPolicy {
+	if s.pricing == nil {
+		return NewHourlyPricing(s.price)
+	}
+	return s.pricing
+}
+
+// Sets opening hours of the club
+func (s *State) SetWorkDay(start, end Time) {
+	s.time_start = start
+	s.time_end = end
+	s.current_time = s.WorkDay().Begin()
+	s.last_time = s.current_time
+	s.scheduleClose()
+}
+
+// Registers close of the club instead of the previous one
+func (s *State) scheduleClose() {
+	s.timers = nil
+	s.closed = false
+	s.schedule(timer{at: s.time_end, kind: TIMER_CLUB_CLOSE})
+}
+
+// Opening hours of the club
+func (s State) WorkDay() WorkDay {
+	return MakeWorkDay(s.time_start, s.time_end)
+}
+
+// Applies input event to the state.
+// Returns events generated by the state, input event is not included
+func (s *State) Apply(event InputEvent) ([]Event, error) {
+	day := s.WorkDay()
+	before := len(s.events)
+
+	// Invalid order of events
+	if !day.LessOrEquals(s.last_time, event.Time()) {
+		return nil, ErrInvalidOrderOfEvent
+	}
+
+	// Everything what happened before the event, including close of the club
+	s.runTimers(event.Time())
+
+	s.last_time = event.Time()
+	s.current_time = event.Time()
+	s.events = append(s.events, event)
+	input_idx := len(s.events) - 1
+
+	event.Translate(s)
+
+	generated := make([]Event, 0, len(s.events)-before-1)
+	for i := before; i < len(s.events); i++ {
+		if i != input_idx {
+			generated = append(generated, s.events[i])
+		}
+	}
+
+	return generated, nil
+}
 
-	s.queue = NewQueue[string](int(size))
+// Time of the event being processed
+func (s State) CurrentTime() Time {
+	return s.current_time
+}
+
+// Number of tables in the club
+func (s State) TableCount() uint {
+	return s.table_count
+}
+
+// Adds generated event to the output
+func (s *State) Emit(event Event) {
+	s.events = append(s.events, event)
 }
 
 // Are we know this client(It is in club)
@@ -52,9 +256,58 @@ func (s State) Known(client string) bool {
 // Add client to known list
 func (s *State) AddClient(client string) {
 	s.client_set[client] = struct{}{}
+	s.startVisit(client)
 }
 
-// Add client to known list
+// Is client seated before others
+func (s State) IsVIP(client string) bool {
+	_, ok := s.vip[client]
+	return ok
+}
+
+// Priority of the client in the waiting queue
+func (s State) queuePriority(client string) int {
+	if s.IsVIP(client) {
+		return PRIORITY_VIP
+	}
+	return PRIORITY_REGULAR
+}
+
+// Put client to the waiting queue
+func (s *State) Enqueue(client string) error {
+	if s.queue.IsFull() {
+		return ErrQueueFull
+	}
+
+	s.EnqueueOverCapacity(client)
+	return nil
+}
+
+// Put client to the waiting queue even if it is full
+func (s *State) EnqueueOverCapacity(client string) {
+	priority := s.queuePriority(client)
+	skipped := s.queue.LowerThan(priority)
+
+	s.queue.ForcePush(client, priority)
+	s.queue_stats.recordLength(s.queue.Len())
+	s.recordQueueLength()
+	s.startWaiting(client)
+	s.scheduleWaitTimeout(client)
+
+	if 0 < skipped {
+		event := NewClientSkippedQueueOutputEvent(s.current_time, client, uint(skipped))
+		s.events = append(s.events, event)
+	}
+}
+
+// Remove client from the waiting queue if it is there
+func (s *State) dequeue(client string) {
+	if s.queue.Remove(func(waiting string) bool { return waiting == client }) {
+		s.recordQueueLength()
+	}
+}
+
+// Remove client from known list and free the table
 func (s *State) ClientLeave(client string) (uint, error) {
 
 	if !s.Known(client) {
@@ -62,7 +315,10 @@ func (s *State) ClientLeave(client string) (uint, error) {
 	}
 
 	delete(s.client_set, client)
-	return s.LeaveTable(client)
+	s.dequeue(client)
+	table, err := s.LeaveTable(client)
+	s.finishVisit(client)
+	return table, err
 }
 
 func (s State) Clients() []string {
@@ -75,14 +331,35 @@ func (s State) Clients() []string {
 	return out
 }
 
+// Fires the rest of timers of the working day up to close of the club
 func (s *State) OnClubClose() {
+	for len(s.timers) != 0 && !s.closed {
+		s.fire()
+	}
+
+	// State without opening hours set has no close timer
+	if !s.closed {
+		s.closeClub()
+	}
+}
+
+// Everybody leaves the club
+func (s *State) closeClub() {
+	s.closed = true
 	s.current_time = s.time_end
 
 	clients := s.Clients()
 	sort.Slice(clients, func(i, j int) bool { return clients[i] < clients[j] })
 
+	// Nobody will wait for a table after close
+	s.queue_stats.WaitingAtClose = uint(s.queue.Len())
+	for !s.queue.IsEmpty() {
+		s.queue.Pop()
+	}
+	s.recordQueueLength()
+
 	for _, cl := range clients {
-		s.LeaveTable(cl)
+		s.ClientLeave(cl)
 		event := NewClientLeftOutputEvent(s.time_end, cl)
 		s.events = append(s.events, event)
 	}
@@ -108,6 +385,31 @@ func (s *State) OccupyTable(number uint, client string) {
 
 	s.clients_current_table[client] = table_id
 
+	// Waiting client may take a free table by itself
+	s.dequeue(client)
+	s.stopWaiting(client)
+	s.markSeated(number, client)
+	s.scheduleSessionEnd(number, client)
+}
+
+// Table where client sits
+func (s State) TableOf(client string) (uint, bool) {
+	table_id, ok := s.clients_current_table[client]
+	return table_id + 1, ok
+}
+
+// Seats the first waiting client at the free table.
+// Reserved table is not given to waiting clients
+func (s *State) SeatFromQueue(table uint) {
+	if s.TableBusy(table) || s.queue.IsEmpty() || s.activeReservation(table, s.current_time) != nil {
+		return
+	}
+
+	client_to_place, _ := s.queue.Pop()
+	s.recordQueueLength()
+	s.OccupyTable(table, client_to_place)
+	occupy_event := NewClientTakenSeatOutputEvent(s.current_time, client_to_place, table)
+	s.events = append(s.events, occupy_event)
 }
 
 func (s *State) LeaveTable(client string) (uint, error) {
@@ -116,11 +418,24 @@ func (s *State) LeaveTable(client string) (uint, error) {
 		delete(s.clients_current_table, client)
 		s.tables_occupation[table_id] = ""
 
-		usage := s.current_time.Diff(s.tables_start_time[table_id])
-		profit := uint(usage.HoursUp()) * s.price
+		session := Session{
+			Client: client,
+			Table:  table_id + 1,
+			Start:  s.tables_start_time[table_id],
+			End:    s.current_time,
+			Paid:   s.clients_paid[client],
+		}
+
+		usage := session.End.Diff(session.Start)
+		profit := s.Pricing().Charge(session)
+		s.clients_paid[client] += profit
 
 		s.tables_profit[table_id] += profit
 		s.tables_usage[table_id] = s.tables_usage[table_id].Add(usage)
+		s.recordSession(table_id, session.Start, usage)
+
+		s.leaveReservations(table_id+1, client)
+		s.billSession(client, BilledSession{session.Table, session.Start, session.End, usage.HoursUp(), profit})
 
 		return table_id + 1, nil
 	}
@@ -128,6 +443,16 @@ func (s *State) LeaveTable(client string) (uint, error) {
 	return 0, errors.New("client don't need to leave table")
 }
 
+// Is there a table which client can take now
+func (s State) HaveEmptyTableFor(client string) bool {
+	for table_nmb := uint(1); table_nmb <= s.table_count; table_nmb++ {
+		if !s.TableBusy(table_nmb) && !s.ReservedForOther(table_nmb, client) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s State) HaveEmptyTable() bool {
 	for table_nmb := uint(1); table_nmb <= s.table_count; table_nmb++ {
 		if !s.TableBusy(table_nmb) {
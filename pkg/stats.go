@@ -0,0 +1,84 @@
+package pkg
+
+// Statistics of the table over the working day
+type TableStats struct {
+	Table       uint
+	Sessions    uint // Number of times the table was taken
+	Usage       Duration
+	Average     Duration // Average session length
+	Longest     Duration
+	Idle        Duration // Free time within opening hours
+	Utilization uint     // Percent of opening hours the table was busy
+	BusiestHour *Time    // Hour of the day when the table was busy the most. nil if it was never taken
+}
+
+// Busy minutes of the table in every hour of the day
+type hourlyUsage [HOURS_IN_DAY]int
+
+// Remembers session at the table for statistics
+func (s *State) recordSession(table_id uint, start Time, usage Duration) {
+	s.tables_sessions[table_id]++
+	if s.tables_longest[table_id] < usage {
+		s.tables_longest[table_id] = usage
+	}
+
+	minutes := usage.Minutes()
+	for i := 0; i < minutes; i++ {
+		hour := TimeFromMinutes(start.InMinutes() + i).Hour
+		s.tables_hourly[table_id][hour]++
+	}
+}
+
+// Statistics of every table
+func (s State) TableStats() []TableStats {
+	day := s.WorkDay()
+	opening := day.Offset(s.time_end) - day.Offset(s.time_start)
+
+	out := make([]TableStats, 0, s.table_count)
+	for i := uint(0); i < s.table_count; i++ {
+		stats := TableStats{
+			Table:    i + 1,
+			Sessions: s.tables_sessions[i],
+			Usage:    s.tables_usage[i],
+			Longest:  s.tables_longest[i],
+		}
+
+		usage := stats.Usage.Minutes()
+		if 0 < stats.Sessions {
+			stats.Average = Duration(usage / int(stats.Sessions))
+		}
+
+		// Session may start before opening, so usage may be longer than opening hours
+		if opening < usage {
+			usage = opening
+		}
+		stats.Idle = Duration(opening - usage)
+		if 0 < opening {
+			stats.Utilization = uint(usage * 100 / opening)
+		}
+
+		stats.BusiestHour = s.busiestHour(i)
+
+		out = append(out, stats)
+	}
+
+	return out
+}
+
+// The first hour of the working day with the most busy minutes
+func (s State) busiestHour(table_id uint) *Time {
+	day := s.WorkDay()
+	first_hour := int(day.Begin().Hour)
+
+	var busiest *Time
+	most := 0
+	for i := 0; i < HOURS_IN_DAY; i++ {
+		hour := (first_hour + i) % HOURS_IN_DAY
+		if most < s.tables_hourly[table_id][hour] {
+			most = s.tables_hourly[table_id][hour]
+			busiest = &Time{uint8(hour), 0}
+		}
+	}
+
+	return busiest
+}
@@ -5,12 +5,23 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 )
 
 var (
 	ErrInvalidTimeFormat = errors.New("invalid time in input files")
 	ErrTimeOutOfRange    = errors.New("time format valid but values are out of range")
+	ErrInvalidDateFormat = errors.New("invalid date in input files")
+	ErrInvalidDuration   = errors.New("invalid duration")
+)
+
+const (
+	HOURS_IN_DAY    = 24
+	MINUTES_IN_HOUR = 60
+	MINUTES_IN_DAY  = HOURS_IN_DAY * MINUTES_IN_HOUR
+
+	DATE_LAYOUT = "2006-01-02"
 )
 
 type Time struct {
@@ -44,7 +55,7 @@ func MakeTime(description string) (Time, error) {
 		return t, err
 	}
 
-	if hours < 0 || 24 <= hours {
+	if hours < 0 || HOURS_IN_DAY <= hours {
 		return t, ErrTimeOutOfRange
 	}
 
@@ -53,7 +64,7 @@ func MakeTime(description string) (Time, error) {
 		return t, err
 	}
 
-	if mins < 0 || 60 <= mins {
+	if mins < 0 || MINUTES_IN_HOUR <= mins {
 		return t, ErrTimeOutOfRange
 	}
 
@@ -67,6 +78,21 @@ func (t Time) String() string {
 	return fmt.Sprintf("%02d:%02d", t.Hour, t.Minutes)
 }
 
+// Time is encoded as "HH:MM" string
+func (t Time) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *Time) UnmarshalText(text []byte) error {
+	parsed, err := MakeTime(string(text))
+	if err != nil {
+		return err
+	}
+
+	*t = parsed
+	return nil
+}
+
 func (left Time) Less(right Time) bool {
 	if left.Hour < right.Hour {
 		return true
@@ -81,36 +107,178 @@ func (left Time) LessOrEquals(right Time) bool {
 	return left.Less(right) || left == right
 }
 
+// Checks that time is in [start, end). If end is less than start,
+// interval is considered crossing midnight
 func (t Time) Between(start, end Time) bool {
+	if end.Less(start) {
+		return start.LessOrEquals(t) || t.Less(end)
+	}
+
 	return start.LessOrEquals(t) && t.Less(end)
 }
 
-func (left Time) Add(right Time) Time {
-	minutes := left.Minutes + right.Minutes
-	add_to_hours := minutes / 60
-	minutes = minutes % 60
+// Time passed from right to left. If left is less than right,
+// it is considered that midnight passed between them
+func (left Time) Diff(right Time) Duration {
+	minutes := left.InMinutes() - right.InMinutes()
+	if minutes < 0 {
+		minutes += MINUTES_IN_DAY
+	}
+
+	return Duration(minutes)
+}
+
+// Time of the day after minutes passed since midnight
+func TimeFromMinutes(minutes int) Time {
+	minutes %= MINUTES_IN_DAY
+	if minutes < 0 {
+		minutes += MINUTES_IN_DAY
+	}
+
+	return Time{uint8(minutes / MINUTES_IN_HOUR), uint8(minutes % MINUTES_IN_HOUR)}
+}
+
+// Time of the day shown by the clock
+func TimeFromClock(clock time.Time) Time {
+	return Time{uint8(clock.Hour()), uint8(clock.Minute())}
+}
+
+// Minutes passed since midnight
+func (t Time) InMinutes() int {
+	return int(t.Hour)*MINUTES_IN_HOUR + int(t.Minutes)
+}
+
+// Length of time in minutes. Unlike Time it is not limited by a day
+type Duration int
+
+func MakeDuration(hours, minutes int) Duration {
+	return Duration(hours*MINUTES_IN_HOUR + minutes)
+}
+
+// Duration is written as "HH:MM", hours may have more digits
+func (d Duration) String() string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+
+	return fmt.Sprintf("%s%02d:%02d", sign, int(d)/MINUTES_IN_HOUR, int(d)%MINUTES_IN_HOUR)
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	description := string(text)
+	sign := 1
+	if strings.HasPrefix(description, "-") {
+		sign = -1
+		description = description[1:]
+	}
+
+	hours_str, mins_str, ok := strings.Cut(description, ":")
+	if !ok || len(hours_str) < 2 || len(mins_str) != 2 {
+		return ErrInvalidDuration
+	}
+
+	hours, err := strconv.ParseUint(hours_str, 10, 31)
+	if err != nil {
+		return ErrInvalidDuration
+	}
+
+	mins, err := strconv.ParseUint(mins_str, 10, 8)
+	if err != nil || MINUTES_IN_HOUR <= mins {
+		return ErrInvalidDuration
+	}
+
+	*d = Duration(sign) * MakeDuration(int(hours), int(mins))
+	return nil
+}
+
+func (d Duration) Minutes() int {
+	return int(d)
+}
+
+func (left Duration) Add(right Duration) Duration {
+	return left + right
+}
+
+func (left Duration) Sub(right Duration) Duration {
+	return left - right
+}
+
+// Started hours. Zero for negative durations
+func (d Duration) HoursUp() uint {
+	if d <= 0 {
+		return 0
+	}
+
+	return uint((int(d) + MINUTES_IN_HOUR - 1) / MINUTES_IN_HOUR)
+}
+
+// Working day of the club. If the club is open overnight, the day begins in
+// the middle of closed hours, so times after midnight go after times before it
+type WorkDay struct {
+	Start Time
+	End   Time
+}
 
-	hours := left.Hour + right.Hour + add_to_hours
+func MakeWorkDay(start, end Time) WorkDay {
+	return WorkDay{start, end}
+}
 
-	return Time{hours, minutes}
+// Is club open through midnight
+func (d WorkDay) Overnight() bool {
+	return d.End.Less(d.Start)
 }
 
-func (left Time) Diff(right Time) Time {
-	hours := left.Hour - right.Hour
-	var minutes = left.Minutes - right.Minutes
-	if left.Minutes < right.Minutes {
-		hours--
-		minutes = 60 - (right.Minutes - left.Minutes)
+// The first minute of the working day
+func (d WorkDay) Begin() Time {
+	if !d.Overnight() {
+		return Time{}
 	}
 
-	return Time{hours, minutes}
+	closed := d.Start.InMinutes() - d.End.InMinutes()
+	return TimeFromMinutes(d.End.InMinutes() + closed/2)
 }
 
-func (left Time) HoursUp() uint8 {
-	hours := left.Hour
-	if 0 < left.Minutes {
-		hours++
+// Minutes since beginning of the working day
+func (d WorkDay) Offset(t Time) int {
+	offset := t.InMinutes() - d.Begin().InMinutes()
+	if offset < 0 {
+		offset += MINUTES_IN_DAY
+	}
+	return offset
+}
+
+func (d WorkDay) Less(left, right Time) bool {
+	return d.Offset(left) < d.Offset(right)
+}
+
+func (d WorkDay) LessOrEquals(left, right Time) bool {
+	return d.Offset(left) <= d.Offset(right)
+}
+
+// Is the club open at the time
+func (d WorkDay) IsOpen(t Time) bool {
+	return t.Between(d.Start, d.End)
+}
+
+// Is the time after closing of the club
+func (d WorkDay) AfterClose(t Time) bool {
+	return d.Less(d.End, t)
+}
+
+// Date of the working day in multi-day logs. Stored in YYYY-MM-DD format,
+// so dates can be compared as strings
+type Date string
+
+func MakeDate(description string) (Date, error) {
+	if _, err := time.Parse(DATE_LAYOUT, description); err != nil {
+		return "", ErrInvalidDateFormat
 	}
 
-	return hours
+	return Date(description), nil
 }
@@ -75,3 +75,98 @@ func TestTimeToString(t *testing.T) {
 		})
 	}
 }
+
+func TestDuration(t *testing.T) {
+	test_cases := []struct {
+		duration Duration
+		str      string
+		hours_up uint
+	}{
+		{0, "00:00", 0},
+		{MakeDuration(1, 0), "01:00", 1},
+		{MakeDuration(1, 1), "01:01", 2},
+		{MakeDuration(300, 15), "300:15", 301},
+		{MakeDuration(0, -30), "-00:30", 0},
+	}
+
+	for _, tc := range test_cases {
+		t.Run("Duration: "+tc.str, func(t *testing.T) {
+			if s := tc.duration.String(); s != tc.str {
+				t.Errorf("Invalid duration string: expected '%s', got '%s'", tc.str, s)
+			}
+
+			if hours := tc.duration.HoursUp(); hours != tc.hours_up {
+				t.Errorf("Invalid started hours: expected %d, got %d", tc.hours_up, hours)
+			}
+
+			var read Duration
+			if err := read.UnmarshalText([]byte(tc.str)); err != nil || read != tc.duration {
+				t.Errorf("Invalid read of '%s': %v, %v", tc.str, read, err)
+			}
+		})
+	}
+}
+
+func TestDurationOverflow(t *testing.T) {
+	var total Duration
+	for i := 0; i < 30; i++ {
+		total = total.Add((Time{19, 0}).Diff(Time{9, 0}))
+	}
+
+	if total != MakeDuration(300, 0) {
+		t.Errorf("Invalid sum of durations: %v", total)
+	}
+
+	if diff := total.Sub(MakeDuration(500, 0)); diff != MakeDuration(-200, 0) {
+		t.Errorf("Invalid negative difference: %v", diff)
+	}
+}
+
+func TestTimeOverMidnight(t *testing.T) {
+	late := Time{23, 30}
+	early := Time{1, 15}
+
+	if diff := early.Diff(late); diff != MakeDuration(1, 45) {
+		t.Errorf("Invalid diff over midnight: %v", diff)
+	}
+
+	if !early.Between(Time{18, 0}, Time{4, 0}) || !late.Between(Time{18, 0}, Time{4, 0}) {
+		t.Errorf("Times must be between 18:00 and 04:00")
+	}
+
+	if (Time{12, 0}).Between(Time{18, 0}, Time{4, 0}) {
+		t.Errorf("12:00 must not be between 18:00 and 04:00")
+	}
+}
+
+func TestWorkDayOrder(t *testing.T) {
+	test_cases := []struct {
+		start, end  Time
+		left, right Time
+		less        bool
+	}{
+		{Time{9, 0}, Time{19, 0}, Time{8, 0}, Time{20, 0}, true},
+		{Time{9, 0}, Time{19, 0}, Time{1, 0}, Time{8, 0}, true},
+		{Time{18, 0}, Time{4, 0}, Time{23, 0}, Time{1, 0}, true},
+		{Time{18, 0}, Time{4, 0}, Time{17, 0}, Time{18, 30}, true},
+		{Time{18, 0}, Time{4, 0}, Time{3, 0}, Time{5, 0}, true},
+		{Time{18, 0}, Time{4, 0}, Time{5, 0}, Time{17, 0}, false},
+		{Time{18, 0}, Time{4, 0}, Time{1, 0}, Time{23, 0}, false},
+	}
+
+	for _, tc := range test_cases {
+		day := MakeWorkDay(tc.start, tc.end)
+		if day.Less(tc.left, tc.right) != tc.less {
+			t.Errorf("Work day %v-%v: expected %v < %v to be %v", tc.start, tc.end, tc.left, tc.right, tc.less)
+		}
+	}
+
+	day := MakeWorkDay(Time{18, 0}, Time{4, 0})
+	if !day.Overnight() || day.Begin() != (Time{11, 0}) {
+		t.Errorf("Invalid beginning of overnight work day: %v", day.Begin())
+	}
+
+	if !day.AfterClose(Time{5, 0}) || day.AfterClose(Time{17, 0}) {
+		t.Errorf("Invalid close check for overnight work day")
+	}
+}
@@ -0,0 +1,253 @@
+package pkg
+
+import "sort"
+
+// Minutes in one cell of the timeline chart
+const TIMELINE_CELL_MINUTES = 15
+
+// Characters used for clients in the timeline chart, in order of arrival
+const timeline_symbols = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// Time the table was taken by the client
+type Occupation struct {
+	Table  uint
+	Client string
+	Start  Time
+	End    Time
+}
+
+// Load of the club in the hour of the day
+type HourlyLoad struct {
+	Hour   Time
+	Tables uint // Most tables busy at the same time
+	Queue  uint // Longest waiting queue
+}
+
+// Length of the waiting queue after it changed
+type queueSample struct {
+	time   Time
+	length int
+}
+
+func (s *State) recordQueueLength() {
+	s.queue_timeline = append(s.queue_timeline, queueSample{s.current_time, s.queue.Len()})
+}
+
+// Beginning of the hour when the club opens. Timeline is drawn from it
+func (s State) timelineStart() Time {
+	return Time{s.time_start.Hour, 0}
+}
+
+// Minutes from the beginning of timeline. Minutes of the opening hour before
+// the opening belong to the end of overnight day
+func (s State) timelineOffset(t Time) int {
+	offset := t.InMinutes() - s.timelineStart().InMinutes()
+	if offset < 0 {
+		offset += MINUTES_IN_DAY
+	}
+	if offset < int(s.time_start.Minutes) {
+		offset += MINUTES_IN_DAY
+	}
+	return offset
+}
+
+// Number of whole hours in the timeline which cover opening hours
+func (s State) timelineHours() int {
+	minutes := s.timelineOffset(s.time_end)
+	if minutes <= s.timelineOffset(s.time_start) {
+		minutes += MINUTES_IN_DAY
+	}
+
+	hours := (minutes + MINUTES_IN_HOUR - 1) / MINUTES_IN_HOUR
+	if hours < 1 {
+		hours = 1
+	}
+	return hours
+}
+
+// Tables taken by clients over the day ordered by table and time.
+// Tables which are still busy are taken until the current time
+func (s State) Occupations() []Occupation {
+	out := make([]Occupation, 0)
+
+	for _, v := range s.visits {
+		for _, session := range v.Sessions {
+			out = append(out, Occupation{session.Table, v.Client, session.Start, session.End})
+		}
+	}
+
+	for i := uint(0); i < s.table_count; i++ {
+		if s.TableBusy(i + 1) {
+			out = append(out, Occupation{i + 1, s.tables_occupation[i], s.tables_start_time[i], s.current_time})
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Table != out[j].Table {
+			return out[i].Table < out[j].Table
+		}
+		return s.timelineOffset(out[i].Start) < s.timelineOffset(out[j].Start)
+	})
+
+	return out
+}
+
+// Clients occupying the table at every minute of the timeline
+func (s State) tableMinutes(occupations []Occupation) [][]string {
+	minutes := s.timelineHours() * MINUTES_IN_HOUR
+
+	out := make([][]string, s.table_count)
+	for i := range out {
+		out[i] = make([]string, minutes)
+	}
+
+	for _, o := range occupations {
+		start := s.timelineOffset(o.Start)
+		end := s.timelineOffset(o.End)
+		for m := start; m < end && m < minutes; m++ {
+			out[o.Table-1][m] = o.Client
+		}
+	}
+
+	return out
+}
+
+// Load of the club in every hour of the timeline
+func (s State) HourlyLoad() []HourlyLoad {
+	hours := s.timelineHours()
+	tables := s.tableMinutes(s.Occupations())
+
+	out := make([]HourlyLoad, hours)
+	for h := range out {
+		out[h].Hour = TimeFromMinutes(s.timelineStart().InMinutes() + h*MINUTES_IN_HOUR)
+
+		for m := h * MINUTES_IN_HOUR; m < (h+1)*MINUTES_IN_HOUR; m++ {
+			busy := uint(0)
+			for i := range tables {
+				if len(tables[i][m]) != 0 {
+					busy++
+				}
+			}
+			if out[h].Tables < busy {
+				out[h].Tables = busy
+			}
+		}
+	}
+
+	// Queue keeps its length until the next change
+	length := 0
+	last_hour := 0
+	for _, sample := range s.queue_timeline {
+		hour := s.timelineOffset(sample.time) / MINUTES_IN_HOUR
+		if hours <= hour {
+			hour = hours - 1
+		}
+
+		for h := last_hour; h <= hour; h++ {
+			if out[h].Queue < uint(length) {
+				out[h].Queue = uint(length)
+			}
+		}
+
+		length = sample.length
+		last_hour = hour
+	}
+
+	for h := last_hour; h < hours; h++ {
+		if out[h].Queue < uint(length) {
+			out[h].Queue = uint(length)
+		}
+	}
+
+	return out
+}
+
+// Client who took the table for the most of every cell of the timeline chart,
+// empty if the table was free, and whether the club was open in the cell
+func (s State) timelineCells() ([][]string, []bool) {
+	tables := s.tableMinutes(s.Occupations())
+	open := s.timelineOffset(s.time_start)
+	close := s.timelineOffset(s.time_end)
+	cells := s.timelineHours() * MINUTES_IN_HOUR / TIMELINE_CELL_MINUTES
+
+	opened := make([]bool, cells)
+	out := make([][]string, 0, s.table_count)
+	for i := range tables {
+		row := make([]string, cells)
+		for c := range row {
+			busy := make(map[string]int)
+			best := ""
+			for m := c * TIMELINE_CELL_MINUTES; m < (c+1)*TIMELINE_CELL_MINUTES; m++ {
+				if m < open || close <= m {
+					continue
+				}
+
+				opened[c] = true
+				client := tables[i][m]
+				if len(client) == 0 {
+					continue
+				}
+
+				busy[client]++
+				if busy[best] < busy[client] {
+					best = client
+				}
+			}
+			row[c] = best
+		}
+		out = append(out, row)
+	}
+
+	return out, opened
+}
+
+// Symbol of every client shown in the timeline chart and clients in order of
+// the symbols. Clients who have not taken a table are not shown
+func (s State) timelineSymbols() (map[string]byte, []string) {
+	shown := make(map[string]bool)
+	cells, _ := s.timelineCells()
+	for _, row := range cells {
+		for _, client := range row {
+			shown[client] = true
+		}
+	}
+
+	symbols := make(map[string]byte)
+	clients := make([]string, 0)
+
+	for _, v := range s.visits {
+		if _, ok := symbols[v.Client]; ok || !shown[v.Client] {
+			continue
+		}
+		symbols[v.Client] = timeline_symbols[len(clients)%len(timeline_symbols)]
+		clients = append(clients, v.Client)
+	}
+
+	return symbols, clients
+}
+
+// Rows of timeline chart for every table. Every cell shows the client who took
+// the table for the most of its minutes, '.' if the table was free for the
+// whole cell and ' ' if the club was closed
+func (s State) timelineRows() []string {
+	symbols, _ := s.timelineSymbols()
+	cells, opened := s.timelineCells()
+
+	out := make([]string, 0, s.table_count)
+	for _, cells_row := range cells {
+		row := make([]byte, len(cells_row))
+		for c, client := range cells_row {
+			switch {
+			case !opened[c]:
+				row[c] = ' '
+			case len(client) == 0:
+				row[c] = '.'
+			default:
+				row[c] = symbols[client]
+			}
+		}
+		out = append(out, string(row))
+	}
+
+	return out
+}
@@ -0,0 +1,81 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// Valid input which probably is a mistake
+var (
+	ErrNoTables  = errors.New("club has no tables")
+	ErrZeroPrice = errors.New("price is zero")
+)
+
+// Fields of header lines in order
+var header_fields = []string{FIELD_TABLES, FIELD_OPEN, FIELD_PRICE}
+
+// Problems found in the input
+type Validation struct {
+	Errors   ParseErrors // Input cannot be processed
+	Warnings ParseErrors // Input can be processed, but it is suspicious
+}
+
+func (v Validation) Valid() bool {
+	return len(v.Errors) == 0
+}
+
+type jsonValidation struct {
+	Valid    bool             `json:"valid"`
+	Errors   []jsonDiagnostic `json:"errors"`
+	Warnings []jsonDiagnostic `json:"warnings"`
+}
+
+// Writes problems in JSON
+func (v Validation) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(jsonValidation{v.Valid(), makeJSONDiagnostics(v.Errors), makeJSONDiagnostics(v.Warnings)})
+}
+
+// Checks every line of the input without writing results.
+// Returned error means the input cannot be read
+func (app *App) Validate() (Validation, error) {
+	app.lenient = true
+	app.output = io.Discard
+
+	var validation Validation
+
+	err := app.Process()
+
+	var parse_errs ParseErrors
+	switch {
+	case err == nil || errors.As(err, &parse_errs):
+	case errors.Is(err, ErrEOF):
+		// Header is not complete
+		validation.Errors = append(validation.Errors, &ParseError{Line: app.line + 1, Column: 1, Field: header_fields[app.line], Err: err})
+	default:
+		return validation, err
+	}
+
+	validation.Errors = append(app.diagnostics, validation.Errors...)
+
+	if !app.header {
+		return validation, nil
+	}
+
+	failed := make(map[int]bool)
+	for _, e := range app.diagnostics {
+		failed[e.Line] = true
+	}
+
+	if app.state.table_count == 0 && !failed[1] {
+		validation.Warnings = append(validation.Warnings, &ParseError{1, 1, FIELD_TABLES, "0", ErrNoTables})
+	}
+
+	if app.state.price == 0 && !failed[3] {
+		validation.Warnings = append(validation.Warnings, &ParseError{3, 1, FIELD_PRICE, "0", ErrZeroPrice})
+	}
+
+	return validation, nil
+}
@@ -0,0 +1,108 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateProblems(t *testing.T) {
+	input := strings.Join([]string{
+		"0",
+		"09:00 19:00",
+		"0",
+		"09:10 1 a",
+		"09:05 1 b",
+		"09:20 1 B",
+		"09:30 2 a 1",
+	}, "\n")
+
+	app := NewApp(strings.NewReader(input), bytes.NewBufferString(""))
+	validation, err := app.Validate()
+	if err != nil {
+		t.Fatalf("validate error: %v", err)
+	}
+
+	expected_errors := []struct {
+		line  int
+		field string
+		err   error
+	}{
+		{5, FIELD_TIME, ErrInvalidOrderOfEvent},
+		{6, FIELD_CLIENT, ErrInvalidEventFormat},
+		{7, FIELD_TABLE, ErrInvalidEventFormat},
+	}
+
+	if len(validation.Errors) != len(expected_errors) {
+		t.Fatalf("Expected %d errors, got: %v", len(expected_errors), validation.Errors)
+	}
+
+	for i, expected := range expected_errors {
+		e := validation.Errors[i]
+		if e.Line != expected.line || e.Field != expected.field || !errors.Is(e, expected.err) {
+			t.Errorf("Expected error in line %d field %s: %v, got: %v", expected.line, expected.field, expected.err, e)
+		}
+	}
+
+	if len(validation.Warnings) != 2 || !errors.Is(validation.Warnings[0], ErrNoTables) || !errors.Is(validation.Warnings[1], ErrZeroPrice) {
+		t.Errorf("Expected warnings about tables and price, got: %v", validation.Warnings)
+	}
+}
+
+func TestValidateHeader(t *testing.T) {
+	test_cases := []struct {
+		name     string
+		input    string
+		line     int
+		field    string
+		warnings int
+	}{
+		{"Empty", "", 1, FIELD_TABLES, 0},
+		{"No price", "3\n09:00 19:00", 3, FIELD_PRICE, 0},
+		{"Invalid tables", "x\n09:00 19:00\n10", 1, FIELD_TABLES, 0},
+		{"Invalid price", "3\n09:00 19:00\n-1", 3, FIELD_PRICE, 0},
+		{"Invalid times with zero price", "3\n09:00\n0", 2, FIELD_ARGUMENTS, 1},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			app := NewApp(strings.NewReader(tc.input), bytes.NewBufferString(""))
+			validation, err := app.Validate()
+			if err != nil {
+				t.Fatalf("validate error: %v", err)
+			}
+
+			if len(validation.Errors) != 1 || validation.Errors[0].Line != tc.line || validation.Errors[0].Field != tc.field {
+				t.Errorf("Expected error in line %d field %s, got: %v", tc.line, tc.field, validation.Errors)
+			}
+
+			if len(validation.Warnings) != tc.warnings {
+				t.Errorf("Expected %d warnings, got: %v", tc.warnings, validation.Warnings)
+			}
+		})
+	}
+}
+
+func TestValidationJSON(t *testing.T) {
+	app := NewApp(strings.NewReader("1\n09:00 19:00\n0\n09:10 1 a\n09:00 1 b"), bytes.NewBufferString(""))
+	validation, err := app.Validate()
+	if err != nil {
+		t.Fatalf("validate error: %v", err)
+	}
+
+	real_output := bytes.NewBufferString("")
+	if err := validation.WriteJSON(real_output); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+
+	var result jsonValidation
+	if err := json.Unmarshal(real_output.Bytes(), &result); err != nil {
+		t.Fatalf("Invalid json: %v\n%s", err, real_output.String())
+	}
+
+	if result.Valid || len(result.Errors) != 1 || result.Errors[0].Line != 5 || len(result.Warnings) != 1 || result.Warnings[0].Field != FIELD_PRICE {
+		t.Errorf("Invalid validation:\n%s", real_output.String())
+	}
+}
@@ -0,0 +1,88 @@
+package pkg
+
+// Time spent at the table with its bill
+type BilledSession struct {
+	Table  uint
+	Start  Time
+	End    Time
+	Hours  uint // Started hours at the table
+	Amount uint // Charged by pricing policy
+}
+
+// Stay of the client in the club from arrival to leave
+type Visit struct {
+	Client   string
+	Arrived  Time
+	Left     Time
+	Present  bool     // Client is still in the club
+	Waiting  Duration // Time spent in the waiting queue
+	Sessions []BilledSession
+
+	waiting       bool
+	waiting_since Time
+}
+
+// Started hours at all tables
+func (v Visit) Hours() uint {
+	var hours uint
+	for _, session := range v.Sessions {
+		hours += session.Hours
+	}
+	return hours
+}
+
+// Money owed for all tables
+func (v Visit) Amount() uint {
+	var amount uint
+	for _, session := range v.Sessions {
+		amount += session.Amount
+	}
+	return amount
+}
+
+// Visits of the working day in order of arrival
+func (s State) Visits() []Visit {
+	out := make([]Visit, 0, len(s.visits))
+	for _, v := range s.visits {
+		out = append(out, *v)
+	}
+	return out
+}
+
+func (s *State) startVisit(client string) {
+	visit := &Visit{Client: client, Arrived: s.current_time, Present: true}
+	s.visits = append(s.visits, visit)
+	s.clients_visit[client] = visit
+}
+
+func (s *State) startWaiting(client string) {
+	if visit, ok := s.clients_visit[client]; ok {
+		visit.waiting = true
+		visit.waiting_since = s.current_time
+	}
+}
+
+func (s *State) stopWaiting(client string) {
+	if visit, ok := s.clients_visit[client]; ok && visit.waiting {
+		wait := s.current_time.Diff(visit.waiting_since)
+		visit.waiting = false
+		visit.Waiting = visit.Waiting.Add(wait)
+		s.queue_stats.recordWait(wait)
+	}
+}
+
+func (s *State) billSession(client string, session BilledSession) {
+	if visit, ok := s.clients_visit[client]; ok {
+		visit.Sessions = append(visit.Sessions, session)
+	}
+}
+
+func (s *State) finishVisit(client string) {
+	s.stopWaiting(client)
+
+	if visit, ok := s.clients_visit[client]; ok {
+		visit.Left = s.current_time
+		visit.Present = false
+		delete(s.clients_visit, client)
+	}
+}
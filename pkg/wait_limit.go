@@ -0,0 +1,26 @@
+package pkg
+
+// Clients wait in the queue until a table is free or the club closes
+const WAIT_UNLIMITED = 0
+
+// Registers leave of the client who began to wait now
+func (s *State) scheduleWaitTimeout(client string) {
+	if s.wait_limit == WAIT_UNLIMITED {
+		return
+	}
+
+	s.scheduleAfter(timer{kind: TIMER_WAIT_TIMEOUT, client: client, since: s.current_time}, s.wait_limit)
+}
+
+// Client leaves the queue and the club if it is still waiting since the
+// timer was registered. Client may still be seated at the last minute of the limit
+func (s *State) timeoutWaiting(t timer) {
+	visit, ok := s.clients_visit[t.client]
+	if !ok || !visit.waiting || visit.waiting_since != t.since {
+		return
+	}
+
+	s.current_time = t.at
+	s.ClientLeave(t.client)
+	s.Emit(NewClientLeftOutputEvent(s.current_time, t.client))
+}